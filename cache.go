@@ -0,0 +1,50 @@
+package confy
+
+import (
+	"os"
+	"strings"
+)
+
+// parsedFile is the data of the file parsed without its imports.
+type parsedFile struct {
+	state fileState
+	data  *configData
+}
+
+// parseCached parses the file without its imports, or returns the data of the previous
+// parse if the file didn't change since then. The returned data must not be modified.
+func (r *reader) parseCached(path string, opts *options) (*configData, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := fileState{modTime: fi.ModTime(), size: fi.Size()}
+
+	// The profile sections of the file depend on the active profiles
+	key := path + "\x00" + strings.Join(opts.profiles, profileSeparator)
+
+	r.cacheMu.Lock()
+	cached, ok := r.cache[key]
+	r.cacheMu.Unlock()
+
+	if ok && cached.state == state {
+		return cached.data, nil
+	}
+
+	data, err := parseSingleFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	if r.cache == nil {
+		r.cache = make(map[string]parsedFile)
+	}
+
+	r.cache[key] = parsedFile{state: state, data: data}
+
+	return data, nil
+}
@@ -0,0 +1,132 @@
+package confy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Config gives access to the merged data of the config sources by the
+// data paths, for example "db.hosts[0]". The environment variables
+// of the "${VAR:default}" values are expanded.
+type Config struct {
+	data *configData
+	path string
+
+	// Options of the Unmarshal method
+	opts *options
+}
+
+// LoadConfig reads the files into the Config.
+func LoadConfig(from ...string) (*Config, error) {
+	return loadConfig(from, newOptions())
+}
+
+func loadConfig(from []string, opts *options) (*Config, error) {
+	data := newConfigData(confyTag)
+
+	if len(from) > 0 {
+		var err error
+
+		data, err = getMultipleFilesData(from, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Config{data: data, opts: opts}, nil
+}
+
+// Get returns the value at the path. The second result is false if the value is not set.
+func (c *Config) Get(path string) (any, bool) {
+	value, ok, err := c.lookup(path)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	value, expanded, envOk := expandValue(value)
+	if expanded && !envOk {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// IsSet checks whether the value at the path is set.
+func (c *Config) IsSet(path string) bool {
+	_, ok := c.Get(path)
+
+	return ok
+}
+
+// Keys returns the sorted keys of the config map.
+func (c *Config) Keys() []string {
+	value, ok, err := c.lookup("")
+	if err != nil || !ok {
+		return nil
+	}
+
+	if m, ok := value.(map[string]any); ok {
+		return slices.Sorted(maps.Keys(m))
+	}
+
+	return nil
+}
+
+// Sub returns the Config of the subtree at the path.
+func (c *Config) Sub(path string) *Config {
+	return &Config{
+		data: c.data,
+		path: joinPath(c.path, path),
+		opts: c.opts,
+	}
+}
+
+// Unmarshal writes the value at the path to the value the 'to' argument points to
+// by the same rules and options as the Read function. The empty path means the whole config.
+func (c *Config) Unmarshal(path string, to any) error {
+	return fillConfigPath(to, c.data, joinPath(c.path, path), c.opts)
+}
+
+func (c *Config) String(path string) (string, error) {
+	return getConfigValue[string](c, path)
+}
+
+func (c *Config) Int(path string) (int, error) {
+	return getConfigValue[int](c, path)
+}
+
+func (c *Config) Float(path string) (float64, error) {
+	return getConfigValue[float64](c, path)
+}
+
+func (c *Config) Bool(path string) (bool, error) {
+	return getConfigValue[bool](c, path)
+}
+
+func (c *Config) Duration(path string) (time.Duration, error) {
+	return getConfigValue[time.Duration](c, path)
+}
+
+func (c *Config) lookup(path string) (any, bool, error) {
+	var root any = c.data.values
+
+	if c.data.items != nil {
+		root = c.data.items
+	}
+
+	return lookupPath(root, joinPath(c.path, path))
+}
+
+func getConfigValue[T any](c *Config, path string) (T, error) {
+	var value T
+
+	if !c.IsSet(path) {
+		return value, fmt.Errorf("the value for the '%s' key is not set", joinPath(c.path, path))
+	}
+
+	err := c.Unmarshal(path, &value)
+
+	return value, err
+}
@@ -1,12 +1,47 @@
 package confy
 
-func Read(to any, from string) error {
-	fileData, fileTag, err := getFileData(from)
+func Read(to any, from string, opts ...Option) error {
+	return read(to, from, newOptions(opts...))
+}
+
+func ReadMany(to any, from ...string) error {
+	return readMany(to, from, newOptions())
+}
+
+// ReadManyWith is like ReadMany, but with the options.
+func ReadManyWith(to any, from []string, opts ...Option) error {
+	return readMany(to, from, newOptions(opts...))
+}
+
+// ReadSection reads only the subtree of the files data at the path
+// (for example "kafka" or "services.auth") into the 'to' argument.
+func ReadSection(to any, path string, from ...string) error {
+	return readSection(to, path, from, newOptions())
+}
+
+// ReadSectionWith is like ReadSection, but with the options.
+func ReadSectionWith(to any, path string, from []string, opts ...Option) error {
+	return readSection(to, path, from, newOptions(opts...))
+}
+
+func ReadEnv(to any, opts ...Option) error {
+	data := newConfigData(confyTag)
+
+	err := fillConfig(to, data, newOptions(opts...))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func read(to any, from string, opts *options) error {
+	data, err := getFileData(from, opts)
 	if err != nil {
 		return err
 	}
 
-	err = fillConfig(to, fileData, fileTag)
+	err = fillConfig(to, data, opts)
 	if err != nil {
 		return err
 	}
@@ -14,13 +49,17 @@ func Read(to any, from string) error {
 	return nil
 }
 
-func ReadMany(to any, from ...string) error {
-	fileData, fileTag, err := getMultipleFilesData(from)
+func readMany(to any, from []string, opts *options) error {
+	return readSection(to, "", from, opts)
+}
+
+func readSection(to any, path string, from []string, opts *options) error {
+	data, err := getMultipleFilesData(from, opts)
 	if err != nil {
 		return err
 	}
 
-	err = fillConfig(to, fileData, fileTag)
+	err = fillConfigPath(to, data, path, opts)
 	if err != nil {
 		return err
 	}
@@ -28,13 +67,46 @@ func ReadMany(to any, from ...string) error {
 	return nil
 }
 
-func ReadEnv(to any) error {
-	fileData := make(map[string]any)
+// Load reads the files (or only the environment variables, if no files
+// are passed) into the new value of the T type and returns it.
+func Load[T any](from ...string) (T, error) {
+	var cfg T
+	var err error
+
+	if len(from) == 0 {
+		err = ReadEnv(&cfg)
+	} else {
+		err = ReadMany(&cfg, from...)
+	}
 
-	err := fillConfig(to, fileData, confyTag)
+	return cfg, err
+}
+
+// MustLoad is like Load, but panics if the config can't be read.
+func MustLoad[T any](from ...string) T {
+	cfg, err := Load[T](from...)
 	if err != nil {
-		return err
+		panic(err)
 	}
 
-	return nil
+	return cfg
+}
+
+// LoadWith reads the config with the reader into the new value of the T type and returns it.
+func LoadWith[T any](r Reader) (T, error) {
+	var cfg T
+
+	err := r.Read(&cfg)
+
+	return cfg, err
+}
+
+// MustLoadWith is like LoadWith, but panics if the config can't be read.
+func MustLoadWith[T any](r Reader) T {
+	cfg, err := LoadWith[T](r)
+	if err != nil {
+		panic(err)
+	}
+
+	return cfg
 }
@@ -0,0 +1,117 @@
+package confy
+
+import (
+	"reflect"
+	"slices"
+)
+
+// change is the change of the config field value.
+type change struct {
+	old any
+	new any
+}
+
+// diffConfigs returns the changes of the config fields by their data paths.
+// The changes of the nested fields are also reported for all their parents.
+func diffConfigs(old, new reflect.Value, dataTag string) map[string]change {
+	changes := make(map[string]change)
+
+	metadata := newRootMeta(old.Type().Name(), "", dataTag)
+
+	diffValue(old, new, metadata, changes)
+
+	return changes
+}
+
+func diffStruct(old, new reflect.Value, metadata *fieldMeta, changes map[string]change) bool {
+	changed := false
+
+	plan := getStructPlan(old.Type(), metadata.dataTag)
+
+	for _, field := range plan.fields {
+		i := field.index
+		fieldMetadata := field.fieldMeta(metadata)
+
+		if !field.exported && !fieldMetadata.inline || fieldMetadata.skip {
+			continue
+		}
+
+		if diffValue(old.Field(i), new.Field(i), fieldMetadata, changes) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func diffValue(old, new reflect.Value, metadata *fieldMeta, changes map[string]change) bool {
+	var changed bool
+
+	switch {
+	case old.Kind() == reflect.Struct && !slices.Contains(specificTypes, old.Type()) && !isSecretType(old.Type()):
+		changed = diffStruct(old, new, metadata, changes)
+
+	case old.Kind() == reflect.Pointer && !old.IsNil() && !new.IsNil():
+		changed = diffValue(old.Elem(), new.Elem(), metadata, changes)
+
+	case !old.CanInterface():
+		// The unexported embedded pointers are never set by the decoder
+		return false
+
+	default:
+		changed = !reflect.DeepEqual(old.Interface(), new.Interface())
+	}
+
+	if changed && metadata.path != "" && !metadata.inline {
+		changes[metadata.path] = change{old: old.Interface(), new: new.Interface()}
+	}
+
+	return changed
+}
+
+// restoreImmutable sets the old values to the immutable fields of the new config
+// and returns the names of the fields whose values were changed.
+func restoreImmutable(old, new reflect.Value, dataTag string) []string {
+	var restored []string
+
+	metadata := newRootMeta(old.Type().Name(), "", dataTag)
+
+	if old.Kind() == reflect.Struct {
+		restoreStruct(old, new, metadata, &restored)
+	}
+
+	return restored
+}
+
+func restoreStruct(old, new reflect.Value, metadata *fieldMeta, restored *[]string) {
+	plan := getStructPlan(old.Type(), metadata.dataTag)
+
+	for _, field := range plan.fields {
+		i := field.index
+		fieldMetadata := field.fieldMeta(metadata)
+
+		if !field.exported && !fieldMetadata.inline || fieldMetadata.skip {
+			continue
+		}
+
+		oldField, newField := old.Field(i), new.Field(i)
+
+		if fieldMetadata.immutable && oldField.CanInterface() {
+			if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+				newField.Set(oldField)
+
+				*restored = append(*restored, fieldMetadata.name)
+			}
+
+			continue
+		}
+
+		if oldField.Kind() == reflect.Pointer && !oldField.IsNil() && !newField.IsNil() {
+			oldField, newField = oldField.Elem(), newField.Elem()
+		}
+
+		if oldField.Kind() == reflect.Struct && !slices.Contains(specificTypes, oldField.Type()) && !isSecretType(oldField.Type()) {
+			restoreStruct(oldField, newField, fieldMetadata, restored)
+		}
+	}
+}
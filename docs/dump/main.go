@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type AppConfig struct {
+	Db DatabaseConfig `confy:"database"`
+}
+
+type DatabaseConfig struct {
+	Host     string `confy:"host"`
+	Username string `confy:"username"`
+
+	// The values of the fields marked with the "secret" tag are masked in the dump.
+	Password string `confy:"password" secret:"true"`
+}
+
+// confy.Dump serializes the loaded config back to YAML, JSON, TOML or dotenv format,
+// so the effective config can be printed at startup:
+//
+//	database:
+//	    host: 0.0.0.0
+//	    password: '******'
+//	    username: admin
+//
+// For the dotenv format the names of the variables are taken from the "env" tags
+// or built from the keys of the fields (for example DATABASE_HOST).
+func main() {
+	var cfg AppConfig
+
+	err := confy.Read(&cfg, "config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	dump, err := confy.Dump(cfg, confy.FormatYAML)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(string(dump))
+}
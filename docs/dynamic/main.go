@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type PoolConfig struct {
+	Size    int `confy:"size"`
+	MaxIdle int `confy:"max-idle" default:"2"`
+}
+
+// confy.LoadConfig reads the files without a struct and gives
+// access to the values by their paths.
+//
+// Map keys are separated by dots, list indexes are set in brackets.
+func main() {
+	cfg, err := confy.LoadConfig("config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	host, _ := cfg.Get("db.hosts[0]")
+	fmt.Println(host)
+
+	// Typed getters return an error if the value is not set or has another type
+	port, err := cfg.Int("db.port")
+	if err != nil {
+		panic(err)
+	}
+
+	timeout, err := cfg.Duration("db.timeout")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(port, timeout)
+
+	// Sub returns the config of the subtree
+	db := cfg.Sub("db")
+
+	fmt.Println(db.Keys())
+	fmt.Println(db.IsSet("pool.size"))
+
+	// Unmarshal writes the subtree to the struct by the same rules as confy.Read
+	var pool PoolConfig
+
+	err = db.Unmarshal("pool", &pool)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(pool)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+// BaseConfig is shared by the configs of all services.
+type BaseConfig struct {
+	Name    string `confy:"name"`
+	Version string `confy:"version"`
+}
+
+type Credentials struct {
+	Username string `confy:"username"`
+	Password string `confy:"password"`
+}
+
+type DatabaseConfig struct {
+	Host string `confy:"host"`
+
+	// The named struct fields can be inlined with the "inline" or "squash" option.
+	Credentials Credentials `confy:",inline"`
+}
+
+// The fields of the embedded structs are read from the data of
+// the parent struct, like in encoding/json.
+//
+// If the embedded struct has a key in the tag (for example `confy:"base"`),
+// it is read as a usual nested struct.
+//
+// If several fields use the same key, an error is returned.
+type AppConfig struct {
+	BaseConfig
+
+	Db DatabaseConfig `confy:"database"`
+}
+
+func main() {
+	var cfg AppConfig
+
+	err := confy.Read(&cfg, "config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Name, cfg.Version)
+	fmt.Println(cfg.Db.Host, cfg.Db.Credentials.Username)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Host   string `confy:"host"`
+	Logger Logger `confy:"log"`
+}
+
+type Logger struct {
+	Level string `confy:"level"`
+}
+
+// confy.Load returns the config of the specified type,
+// so you don't need to declare a variable and pass a pointer to it.
+//
+// Without files only environment variables are read.
+// confy.LoadWith does the same with the Reader.
+//
+// confy.MustLoad and confy.MustLoadWith panic if the config can't be read.
+func main() {
+	cfg, err := confy.Load[Config]("config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg)
+
+	fmt.Println(confy.MustLoad[Config]("config.yaml"))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Name string `yaml:"name"`
+
+	Db struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"db"`
+
+	Log struct {
+		Level string `yaml:"level"`
+	} `yaml:"log"`
+}
+
+// The config file can import other files with the "$import" key.
+// Its value is a path or a list of paths relative to the importing file:
+//
+//  1. The paths can be glob patterns, the matched files are imported in the lexical order.
+//  2. The paths starting with '?' are optional and are skipped if they don't exist.
+//  3. The imported files can import other files, the import cycles are reported as errors.
+//  4. The imported files must be inside the directory of the file passed to confy,
+//     the symbolic links are resolved before the check.
+//
+// The imported files are merged with each other in the listed order, their conflicts
+// are resolved by the merge policy (see the merge example). The values of the importing
+// file always override the values of its imports.
+// In the JSON and TOML files the key is written as "$import".
+func main() {
+	var cfg Config
+
+	err := confy.Read(&cfg, "./config/app.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Name)      // orders
+	fmt.Println(cfg.Db)        // {localhost 5432}
+	fmt.Println(cfg.Log.Level) // debug
+}
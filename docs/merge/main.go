@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Server struct {
+	Name string `confy:"name"`
+	Host string `confy:"host"`
+}
+
+type Config struct {
+	Log struct {
+		Level string `confy:"level"`
+	} `confy:"log"`
+
+	Servers []Server `confy:"servers"`
+	Tags    []string `confy:"tags"`
+}
+
+// By default, confy returns an error if several files set the same key.
+// The merge policy can be changed with the confy.WithMergePolicy option:
+//
+//  1. confy.MergeConflictError: an error is returned (default).
+//  2. confy.MergeLastWins: the value of the last file is used.
+//  3. confy.MergeFirstWins: the value of the first file is used.
+//
+// The lists can be merged by the strategy set for their path
+// with the confy.WithListStrategy option:
+//
+//  1. confy.ListReplace: the list of the last file is used.
+//  2. confy.ListAppend: the lists are concatenated.
+//  3. confy.ListMergeByKey("name"): the items with the same "name" are merged,
+//     the other items are appended.
+//
+// The options can be used with confy.Read for directories, confy.ReadManyWith
+// and the SetMergePolicy and SetListStrategy methods of the Reader.
+func main() {
+	var cfg Config
+
+	err := confy.ReadManyWith(&cfg, []string{"base.yaml", "override.yaml"},
+		confy.WithMergePolicy(confy.MergeLastWins),
+		confy.WithListStrategy("servers", confy.ListMergeByKey("name")),
+		confy.WithListStrategy("tags", confy.ListAppend),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Log.Level) // debug
+	fmt.Println(cfg.Servers)   // [{primary 10.0.0.1} {replica 10.0.0.3} {backup 10.0.0.4}]
+	fmt.Println(cfg.Tags)      // [base override]
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type PluginConfig struct {
+	Enabled bool           `confy:"enabled"`
+	Options map[string]any `confy:"options"`
+}
+
+type Rule struct {
+	Name string `confy:"name"`
+	Path string `confy:"path"`
+}
+
+// Besides structs, the config can be read into maps, slices
+// and any other types that can be read into the struct fields.
+//
+// The elements of maps and slices can be structs as well.
+//
+// To read a slice, the root of the file must be a list.
+func main() {
+	var raw map[string]any
+
+	err := confy.Read(&raw, "plugins.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(raw)
+
+	plugins, err := confy.Load[map[string]PluginConfig]("plugins.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(plugins)
+
+	var rules []Rule
+
+	err = confy.Read(&rules, "rules.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(rules)
+}
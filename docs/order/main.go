@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	App struct {
+		Name string `confy:"name"`
+	} `confy:"app"`
+
+	Log struct {
+		Level string `confy:"level"`
+	} `confy:"log"`
+
+	Db struct {
+		Host string `confy:"host"`
+		Port int    `confy:"port"`
+	} `confy:"db"`
+}
+
+// The files of the directory are merged in a deterministic order,
+// so the later files override the values of the earlier ones.
+// The order can be changed with the confy.WithFileOrder option:
+//
+//  1. confy.OrderLexical: the files are sorted by their paths (default).
+//  2. confy.OrderNumericPrefix: the files are sorted by the numeric prefixes
+//     of their names, so "2-defaults.yaml" is read before "10-base.yaml".
+//
+// The order can also be set explicitly with the manifest file. If the directory
+// contains the file with the name passed to the confy.WithManifest option, only the
+// files listed in it are read, in the listed order.
+//
+// The Reader has the SetFileOrder and SetManifest methods for the same options.
+func main() {
+	var cfg Config
+
+	err := confy.Read(&cfg, "./config/conf.d",
+		confy.WithFileOrder(confy.OrderNumericPrefix),
+		confy.WithMergePolicy(confy.MergeLastWins),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.App.Name)  // orders
+	fmt.Println(cfg.Log.Level) // debug
+	fmt.Println(cfg.Db.Port)   // 5432
+
+	var manifestCfg Config
+
+	err = confy.Read(&manifestCfg, "./config/manifest",
+		confy.WithManifest("order.txt"),
+		confy.WithMergePolicy(confy.MergeLastWins),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(manifestCfg.Log.Level) // error
+}
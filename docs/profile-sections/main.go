@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Db struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"db"`
+
+	Log struct {
+		Level  string `yaml:"level"`
+		Format string `yaml:"format" default:"text"`
+	} `yaml:"log"`
+}
+
+// The YAML file can contain several documents separated by "---".
+// The documents are merged in their order, the later documents override
+// the values of the earlier ones.
+//
+// The document with the "$profile" key (a profile name or a list of names)
+// is merged only if one of its profiles is active. The profiles are activated
+// with the confy.WithProfiles option. The Reader activates the documents
+// of its resolved profiles (see the reader examples).
+func main() {
+	var localCfg Config
+
+	err := confy.Read(&localCfg, "config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(localCfg.Db)  // {localhost 5432}
+	fmt.Println(localCfg.Log) // {debug text}
+
+	var prodCfg Config
+
+	err = confy.Read(&prodCfg, "config.yaml", confy.WithProfiles("prod"))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(prodCfg.Db)  // {db.prod.internal 5432}
+	fmt.Println(prodCfg.Log) // {info json}
+}
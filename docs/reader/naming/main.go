@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Value string `confy:"value"`
+}
+
+// By default, Reader reads the "local" profile from the
+// "./config/{profile}.{json|yaml|yml|toml}" file or the "./config/{profile}" directory.
+//
+// The layout can be changed:
+//
+//  1. Reader.SetNameTemplate sets the template of the source names with the "{profile}"
+//     and "{app}" placeholders (for example "application-{profile}.yaml").
+//     If the template has no extension, the source can be a directory or a file
+//     with any supported extension.
+//  2. Reader.SetAppName sets the value of the "{app}" placeholder.
+//  3. Reader.SetRootPaths sets the root paths in the priority order, the profile is
+//     read from the first root path that contains its source. The environment
+//     variables in the paths are expanded (for example "$XDG_CONFIG_HOME/app").
+//  4. Reader.SetDefaultProfile sets the profile that is used if the ENVIRONMENT
+//     variable is not set.
+func main() {
+	reader := confy.NewReader().
+		SetRootPaths("./deploy", "./config").
+		SetNameTemplate("application-{profile}.yaml").
+		SetDefaultProfile("dev")
+
+	var devCfg Config
+
+	err := reader.Read(&devCfg)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(devCfg.Value) // deploy-dev
+
+	os.Setenv("ENVIRONMENT", "prod")
+
+	var prodCfg Config
+
+	err = reader.Read(&prodCfg)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(prodCfg.Value) // config-prod
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Region string `confy:"region"`
+
+	Db struct {
+		Host string `confy:"host"`
+		Port int    `confy:"port"`
+	} `confy:"db"`
+
+	Log struct {
+		Level string `confy:"level"`
+	} `confy:"log"`
+}
+
+// The ENVIRONMENT variable can list several active profiles separated by commas.
+// Their sources are read in the listed order.
+//
+// A profile can declare its parent with the "$extends" key of its file,
+// so it contains only the differences from the parent. The parents are read
+// before their children and every profile is read once. The inheritance cycles
+// are reported as errors.
+//
+// The Profiles method of the Reader returns the resolved chain of the profiles.
+//
+// The profiles override the values of the shared sources and the earlier
+// profiles whatever the merge policy is. The policy applies to the files
+// of the same profile and to the shared sources among themselves.
+func main() {
+	os.Setenv("ENVIRONMENT", "eu-west,canary")
+
+	reader := confy.NewReader()
+
+	profiles, err := reader.Profiles()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(profiles) // [base prod eu-west canary]
+
+	var cfg Config
+
+	err = reader.Read(&cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Region)    // eu-west-1
+	fmt.Println(cfg.Db)        // {db.prod.internal 5432}
+	fmt.Println(cfg.Log.Level) // debug
+}
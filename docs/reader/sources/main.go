@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Log struct {
+		Level string `confy:"level"`
+	} `confy:"log"`
+
+	Metrics struct {
+		Port int `confy:"port"`
+	} `confy:"metrics"`
+
+	Db struct {
+		Host string `confy:"host"`
+	} `confy:"db"`
+
+	Cache struct {
+		Size int `confy:"size"`
+	} `confy:"cache"`
+}
+
+// Besides the paths in the profile directory added with Reader.AddSource,
+// the sources can be declared with Reader.AddSources:
+//
+//  1. Path: the path relative to the profile directory. It can be a glob pattern.
+//  2. Shared: the path is relative to the root path and is read for all profiles
+//     before the profile sources (for example "common/*.yaml").
+//  3. Optional: the source is skipped if it doesn't exist.
+//  4. Profiles: the source is read only for these profiles.
+//
+// The shared sources can be added when ReadAll = true, the other sources can't.
+func main() {
+	var cfg Config
+
+	err := confy.NewReader().
+		SetReadAll(false).
+		AddSource("db.yaml").
+		AddSources(
+			confy.Source{Path: "common/*.yaml", Shared: true},
+			confy.Source{Path: "override.yaml", Optional: true},
+			confy.Source{Path: "cache.yaml", Profiles: []string{"prod"}},
+		).
+		Read(&cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Log.Level)    // info
+	fmt.Println(cfg.Metrics.Port) // 9090
+	fmt.Println(cfg.Db.Host)      // localhost
+	fmt.Println(cfg.Cache.Size)   // 0
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	// The fields that are read only at startup can be marked with the
+	// `reload:"restart"` (or `immutable:"true"`) tag. If they are changed,
+	// they keep the old values and the error is passed to the OnError callbacks,
+	// but the other fields are still applied.
+	Addr string `confy:"addr" reload:"restart"`
+
+	Level string `confy:"level"`
+}
+
+// If the config implements confy.Validator, it is validated after every load.
+// A config that fails the validation is not published.
+func (c *Config) Validate() error {
+	if c.Level == "" {
+		return errors.New("level is empty")
+	}
+
+	return nil
+}
+
+// confy.Watch reads the config with the reader and polls the files of the
+// profile sources. When they change, the config is read again and the new
+// value is published atomically, so Load always returns a complete config.
+//
+// If the reload fails (invalid file, failed validation), the previous config
+// is kept and the error is passed to the OnError callbacks.
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The config is also reloaded on SIGHUP. The reload signals can be changed
+	// with SetReloadSignals (without arguments they are disabled).
+	reader := confy.NewReader().
+		SetWatchInterval(time.Second).
+		SetReloadSignals(syscall.SIGHUP, syscall.SIGUSR1)
+
+	cfg, err := confy.Watch[Config](ctx, reader)
+	if err != nil {
+		panic(err)
+	}
+
+	// OnChange is called only if the reloaded config differs from the current one.
+	cfg.OnChange(func(old, new *Config) {
+		fmt.Printf("level changed: %s -> %s\n", old.Level, new.Level)
+	})
+
+	// Subscribe calls the callback only if the value of the field
+	// with the specified path changed.
+	cfg.Subscribe("level", func(old, new any) {
+		fmt.Printf("new level: %v\n", new)
+	})
+
+	cfg.OnError(func(err error) {
+		fmt.Println(err)
+	})
+
+	// OnReload reports the result of every reload attempt.
+	cfg.OnReload(func(result confy.ReloadResult) {
+		fmt.Printf("reload by %s: %v\n", result.Trigger, result.Err)
+	})
+
+	// The config can be reloaded manually, for example from the admin endpoint.
+	// Concurrent reloads are serialized.
+	if err := cfg.Reload(ctx); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(cfg.Load().Level)
+
+	<-ctx.Done()
+}
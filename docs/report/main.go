@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gosuit/confy"
+)
+
+type DbConfig struct {
+	Host     string `confy:"host"`
+	Port     int    `confy:"port" default:"5432"`
+	Username string `confy:"username" env:"DB_USERNAME"`
+	Password string `confy:"password"`
+	Name     string `confy:"name"`
+}
+
+// The report shows where the value of every field came from:
+//
+//	DbConfig.Host: file key 'host' in 'config.yaml:1:7'
+//	DbConfig.Name: code
+//	DbConfig.Password: default 'DB_PASSWORD' key 'password' in 'config.yaml:2:11'
+//	DbConfig.Port: default
+//	DbConfig.Username: env 'DB_USERNAME'
+//
+// The report can be logged at startup or marshaled to JSON.
+// For the Reader use the SetReport method.
+func main() {
+	os.Setenv("DB_USERNAME", "admin")
+
+	var cfg DbConfig
+	var report confy.Report
+
+	err := confy.Read(&cfg, "config.yaml", confy.WithReport(&report))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(report)
+}
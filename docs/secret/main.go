@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gosuit/confy"
+)
+
+type DbConfig struct {
+	Host string `confy:"host"`
+
+	// confy.Secret can be read from files, environment variables and
+	// default values in the same way as the wrapped type.
+	Password confy.Secret[string] `confy:"password"`
+}
+
+// The value of confy.Secret is never printed: fmt, JSON marshaling
+// and slog show "******" instead of it.
+//
+// Use the Reveal method to get the value.
+func main() {
+	var cfg DbConfig
+
+	err := confy.Read(&cfg, "config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg)                              // {0.0.0.0 ******}
+	slog.Info("config", "password", cfg.Password) // password=******
+
+	fmt.Println(cfg.Password.Reveal()) // root
+}
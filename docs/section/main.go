@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+// The config of the library that doesn't know the root config struct.
+type KafkaConfig struct {
+	Brokers []string `confy:"brokers"`
+	Group   string   `confy:"group"`
+	Retries int      `confy:"retries" default:"3"`
+}
+
+// confy.ReadSection reads only the subtree of the config at the specified path.
+// Nested sections are separated by dots, for example "services.kafka".
+// If the section is not set, an error is returned.
+//
+// confy.ReadSectionWith takes the options, and the Reader has
+// the ReadSection method as well.
+func main() {
+	var cfg KafkaConfig
+
+	err := confy.ReadSection(&cfg, "kafka", "config.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg)
+}
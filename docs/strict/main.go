@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Host    string        `confy:"host"`
+	Timeout time.Duration `confy:"timeout" default:"1s"`
+}
+
+// By default, keys from the files that don't correspond to any
+// field of the struct are ignored.
+//
+// In strict mode confy returns an error for every unknown key
+// with its position and a suggestion of the closest known key:
+//
+//	unknown key 'tiemout' in 'config.yaml:4:10', did you mean 'timeout'?
+//
+// The keys of the structs in the slices, arrays and maps are checked too,
+// for example 'servers[0].hots'.
+//
+// For the Reader use the SetStrict(true) method.
+func main() {
+	var cfg Config
+
+	err := confy.Read(&cfg, "config.yaml", confy.Strict())
+	if err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gosuit/confy"
+)
+
+// The tags have the "name,option,option" format.
+type Config struct {
+	MaxConns    int           `confy:"maxconns"`
+	PoolSize    int           `confy:"poolsize"`
+	ReadTimeout time.Duration `confy:"readtimeout"`
+
+	// The field with the "-" tag is ignored by confy.
+	Cache map[string]string `confy:"-"`
+
+	// The field with the "omitempty" option is omitted
+	// in the confy.Dump result if it has the zero value.
+	Comment string `confy:"comment,omitempty"`
+}
+
+// By default, the keys of the data must be equal to the keys of the fields.
+// The key matching policy can be changed with the confy.WithKeyMatching option
+// (or the SetKeyMatching method of the Reader):
+//
+//  1. confy.KeyMatchExact: the keys must be equal.
+//  2. confy.KeyMatchCaseInsensitive: "maxConns" matches "maxconns".
+//  3. confy.KeyMatchNormalized: the case and the "_" and "-" separators
+//     are ignored, so "pool_size", "pool-size" and "poolSize" match "poolsize".
+func main() {
+	var cfg Config
+
+	err := confy.Read(&cfg, "config.json", confy.WithKeyMatching(confy.KeyMatchNormalized))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.MaxConns, cfg.PoolSize, cfg.ReadTimeout)
+}
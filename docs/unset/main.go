@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gosuit/confy"
+)
+
+type Config struct {
+	Proxy struct {
+		Host string `confy:"host"`
+		Port int    `confy:"port"`
+	} `confy:"proxy"`
+
+	Log struct {
+		Level   string   `confy:"level"`
+		Outputs []string `confy:"outputs" default:"stdout"`
+	} `confy:"log"`
+
+	Timeout int `confy:"timeout" default:"30"`
+}
+
+// The override file can remove the key set by the previous files with the
+// "~delete" value or with the "!unset" YAML tag. The removed field gets the value
+// of its default tag or the zero value, as if the key was never set.
+//
+// The markers are applied regardless of the merge policy and can be used in
+// all supported formats ("~delete" in JSON and TOML files).
+func main() {
+	var cfg Config
+
+	err := confy.ReadMany(&cfg, "base.yaml", "prod.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Proxy)       // { 0}
+	fmt.Println(cfg.Log.Level)   // info
+	fmt.Println(cfg.Log.Outputs) // [stdout]
+	fmt.Println(cfg.Timeout)     // 30
+}
@@ -0,0 +1,308 @@
+package confy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of the dumped config.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	FormatEnv  Format = "env"
+)
+
+const redacted = "******"
+
+// Dump serializes the config struct to the format. The keys are resolved
+// in the same way as when reading, the values of the fields with the
+// "secret" tag and of the Secret type are masked.
+func Dump(cfg any, format Format) ([]byte, error) {
+	v := reflect.ValueOf(cfg)
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, errors.New("the 'cfg' argument must be a struct or a non-nil pointer to struct")
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("the 'cfg' argument must be a struct or a non-nil pointer to struct")
+	}
+
+	metadata := newRootMeta(v.Type().Name(), "", "")
+
+	switch format {
+	case FormatYAML:
+		metadata.dataTag = yamlTag
+
+		return yaml.Marshal(dumpStruct(v, metadata))
+
+	case FormatJSON:
+		metadata.dataTag = jsonTag
+
+		b, err := json.MarshalIndent(dumpStruct(v, metadata), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		return append(b, '\n'), nil
+
+	case FormatTOML:
+		metadata.dataTag = tomlTag
+
+		var b bytes.Buffer
+
+		if err := toml.NewEncoder(&b).Encode(dumpStruct(v, metadata)); err != nil {
+			return nil, err
+		}
+
+		return b.Bytes(), nil
+
+	case FormatEnv:
+		metadata.dataTag = confyTag
+
+		var b bytes.Buffer
+
+		dumpEnv(&b, v, metadata)
+
+		return b.Bytes(), nil
+
+	default:
+		return nil, fmt.Errorf("confy doesn`t support '%s' format", format)
+	}
+}
+
+func dumpStruct(s reflect.Value, metadata *fieldMeta) map[string]any {
+	data := make(map[string]any)
+
+	plan := getStructPlan(s.Type(), metadata.dataTag)
+
+	for _, field := range plan.fields {
+		i := field.index
+		fieldMetadata := field.fieldMeta(metadata)
+
+		if !field.exported && !fieldMetadata.inline || fieldMetadata.skip {
+			continue
+		}
+
+		if fieldMetadata.omitEmpty && s.Field(i).IsZero() {
+			continue
+		}
+
+		value, ok := dumpValue(s.Field(i), fieldMetadata)
+		if !ok {
+			continue
+		}
+
+		if inlineData, isMap := value.(map[string]any); isMap && fieldMetadata.inline {
+			maps.Copy(data, inlineData)
+		} else {
+			data[fieldMetadata.key] = value
+		}
+	}
+
+	return data
+}
+
+func dumpValue(f reflect.Value, metadata *fieldMeta) (any, bool) {
+	if f.Kind() == reflect.Pointer || f.Kind() == reflect.Interface {
+		if f.IsNil() {
+			return nil, false
+		}
+
+		return dumpValue(f.Elem(), metadata)
+	}
+
+	if metadata.secret || isSecretType(f.Type()) {
+		return redacted, true
+	}
+
+	if value, ok := dumpSpecificValue(f, metadata); ok {
+		return value, true
+	}
+
+	switch f.Kind() {
+	case reflect.Struct:
+		return dumpStruct(f, metadata), true
+
+	case reflect.Map:
+		data := make(map[string]any)
+
+		for _, k := range f.MapKeys() {
+			if value, ok := dumpValue(f.MapIndex(k), metadata); ok {
+				data[fmt.Sprint(k.Interface())] = value
+			}
+		}
+
+		return data, true
+
+	case reflect.Array, reflect.Slice:
+		data := make([]any, 0, f.Len())
+
+		for i := range f.Len() {
+			if value, ok := dumpValue(f.Index(i), metadata); ok {
+				data = append(data, value)
+			}
+		}
+
+		return data, true
+
+	default:
+		return f.Interface(), true
+	}
+}
+
+// dumpSpecificValue converts the values of the specific types
+// to the strings in the same format in which they are read.
+func dumpSpecificValue(f reflect.Value, metadata *fieldMeta) (string, bool) {
+	switch f.Type() {
+	case reflect.TypeOf(time.Time{}):
+		layout, ok := metadata.layout, metadata.hasLayout
+		if !ok {
+			layout = time.RFC3339
+		}
+
+		return f.Interface().(time.Time).Format(layout), true
+
+	case reflect.TypeOf(url.URL{}):
+		u := f.Interface().(url.URL)
+
+		return u.String(), true
+
+	case reflect.TypeOf(time.Location{}):
+		l := f.Interface().(time.Location)
+
+		return l.String(), true
+
+	case reflect.TypeOf(time.Duration(0)):
+		return f.Interface().(time.Duration).String(), true
+	}
+
+	return "", false
+}
+
+// dumpEnv writes the config in the dotenv format. The variable names are taken
+// from the "env" tags or built from the field keys.
+func dumpEnv(b *bytes.Buffer, s reflect.Value, metadata *fieldMeta) {
+	plan := getStructPlan(s.Type(), metadata.dataTag)
+
+	for _, field := range plan.fields {
+		i := field.index
+		fieldMetadata := field.fieldMeta(metadata)
+
+		if !field.exported && !fieldMetadata.inline || fieldMetadata.skip {
+			continue
+		}
+
+		if fieldMetadata.omitEmpty && s.Field(i).IsZero() {
+			continue
+		}
+
+		f := s.Field(i)
+
+		for f.Kind() == reflect.Pointer || f.Kind() == reflect.Interface {
+			if f.IsNil() {
+				break
+			}
+
+			f = f.Elem()
+		}
+
+		if f.Kind() == reflect.Pointer || f.Kind() == reflect.Interface {
+			continue
+		}
+
+		if f.Kind() == reflect.Struct && !slices.Contains(specificTypes, f.Type()) && !isSecretType(f.Type()) && !fieldMetadata.secret {
+			dumpEnv(b, f, fieldMetadata)
+
+			continue
+		}
+
+		name, ok := fieldMetadata.env, fieldMetadata.hasEnv
+		if !ok {
+			name = getEnvName(fieldMetadata.path)
+		}
+
+		fmt.Fprintf(b, "%s=%s\n", name, quoteEnvValue(dumpEnvValue(f, fieldMetadata)))
+	}
+}
+
+func dumpEnvValue(f reflect.Value, metadata *fieldMeta) string {
+	if metadata.secret || isSecretType(f.Type()) {
+		return redacted
+	}
+
+	if value, ok := dumpSpecificValue(f, metadata); ok {
+		return value
+	}
+
+	switch f.Kind() {
+	case reflect.Map:
+		items := make([]string, 0, f.Len())
+
+		for _, k := range f.MapKeys() {
+			items = append(items, fmt.Sprintf("%v:%s", k.Interface(), dumpEnvValue(f.MapIndex(k), metadata)))
+		}
+
+		slices.Sort(items)
+
+		return strings.Join(items, metadata.separator)
+
+	case reflect.Array, reflect.Slice:
+		items := make([]string, 0, f.Len())
+
+		for i := range f.Len() {
+			items = append(items, dumpEnvValue(f.Index(i), metadata))
+		}
+
+		return strings.Join(items, metadata.separator)
+
+	case reflect.Pointer, reflect.Interface:
+		if f.IsNil() {
+			return ""
+		}
+
+		return dumpEnvValue(f.Elem(), metadata)
+
+	default:
+		return fmt.Sprint(f.Interface())
+	}
+}
+
+// getEnvName converts the data path to the name of the variable, for example "db.max-conns" to "DB_MAX_CONNS".
+func getEnvName(path string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, path)
+}
+
+func quoteEnvValue(value string) string {
+	if strings.ContainsAny(value, " \t\n\r#'\"\\=$") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
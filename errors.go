@@ -0,0 +1,25 @@
+package confy
+
+// FieldError is returned when the value for a config field can't be set.
+type FieldError struct {
+	// Field is the full name of the field, for example "Config.Db.Port".
+	Field string
+
+	// Position is the place of the value in the source file in the "file:line:col"
+	// format. It is empty if the value was not read from a file.
+	Position string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Position != "" {
+		return e.Position + ": " + e.Err.Error()
+	}
+
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
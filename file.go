@@ -1,7 +1,9 @@
 package confy
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -19,47 +21,136 @@ var (
 	validExtensions = []string{".yaml", ".yml", ".json", ".toml", ".env"}
 )
 
-func getFileData(path string) (map[string]any, string, error) {
+// configData is the merged data of the config sources.
+type configData struct {
+	values    map[string]any
+	positions positions
+	tag       string
+
+	// Items of the source whose root is a list
+	items []any
+}
+
+func newConfigData(tag string) *configData {
+	return &configData{
+		values:    make(map[string]any),
+		positions: make(positions),
+		tag:       tag,
+	}
+}
+
+// deleteKey deletes the root key with its positions.
+func (d *configData) deleteKey(key string) {
+	delete(d.values, key)
+
+	for path := range d.positions {
+		if _, ok := cutPathPrefix(path, key); ok {
+			delete(d.positions, path)
+		}
+	}
+}
+
+func getFileData(path string, opts *options) (*configData, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
-		return nil, "", fmt.Errorf("error while '%s' path read: %s", path, err.Error())
+		return nil, fmt.Errorf("error while '%s' path read: %s", path, err.Error())
 	}
 
 	if fi.IsDir() {
-		paths, err := getValidFiles(path)
+		paths, err := getValidFiles(path, opts)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 
-		fileData, err := parseMultipleFiles(paths)
+		data, err := parseMultipleFiles(paths, opts)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 
-		return fileData, getMultipleFilesTag(paths), nil
+		data.tag = getMultipleFilesTag(paths)
+
+		return data, nil
 	} else {
-		fileData, err := parseFile(path)
+		data, err := parseFile(path, opts)
 		if err != nil {
-			return nil, "", err
+			return nil, err
+		}
+
+		dropDeleteMarkers(data.values, "", data.positions)
+		dropDeleteMarkers(data.items, "", data.positions)
+
+		data.tag = getFileTag(path)
+
+		return data, nil
+	}
+}
+
+func getMultipleFilesData(paths []string, opts *options) (*configData, error) {
+	return getLayersData([][]string{paths}, opts)
+}
+
+// getLayersData reads the layers of the sources. The files of the layer are merged
+// by the merge policy, and the layer overrides the values of the previous layers.
+func getLayersData(layers [][]string, opts *options) (*configData, error) {
+	data := newConfigData(confyTag)
+	files := make([]string, 0)
+
+	overrideOpts := *opts
+	overrideOpts.mergePolicy = MergeLastWins
+
+	for i, paths := range layers {
+		layerFiles, err := getSourceFiles(paths, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(layerFiles) == 0 {
+			continue
+		}
+
+		layerData := newConfigData(confyTag)
+
+		for _, path := range layerFiles {
+			newData, err := parseFile(path, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			// The markers of the layer delete the values of the previous layers
+			if err := mergeDataMarkers(layerData, newData, path, opts, i > 0); err != nil {
+				return nil, err
+			}
 		}
 
-		return fileData, getFileTag(path), nil
+		if err := mergeData(data, layerData, layerFiles[0], &overrideOpts); err != nil {
+			return nil, err
+		}
+
+		files = append(files, layerFiles...)
 	}
+
+	dropDeleteMarkers(data.values, "", data.positions)
+	dropDeleteMarkers(data.items, "", data.positions)
+
+	data.tag = getMultipleFilesTag(files)
+
+	return data, nil
 }
 
-func getMultipleFilesData(paths []string) (map[string]any, string, error) {
+// getSourceFiles returns the files of the file and directory sources.
+func getSourceFiles(paths []string, opts *options) ([]string, error) {
 	files := make([]string, 0)
 
 	for _, path := range paths {
 		fi, err := os.Stat(path)
 		if err != nil {
-			return nil, "", fmt.Errorf("error while '%s' path read: %s", path, err.Error())
+			return nil, fmt.Errorf("error while '%s' path read: %s", path, err.Error())
 		}
 
 		if fi.IsDir() {
-			newFiles, err := getValidFiles(path)
+			newFiles, err := getValidFiles(path, opts)
 			if err != nil {
-				return nil, "", err
+				return nil, err
 			}
 
 			files = append(files, newFiles...)
@@ -68,25 +159,23 @@ func getMultipleFilesData(paths []string) (map[string]any, string, error) {
 		}
 	}
 
-	fileData, err := parseMultipleFiles(files)
-	if err != nil {
-		return nil, "", err
-	}
-
-	return fileData, getMultipleFilesTag(files), nil
+	return files, nil
 }
 
-func parseFile(path string) (map[string]any, error) {
-	var data map[string]any
+// parseSingleFile parses the file without its imports.
+func parseSingleFile(path string, opts *options) (*configData, error) {
+	data := newConfigData(getFileTag(path))
+
+	var root any
 	var err error
 
 	switch ext := strings.ToLower(filepath.Ext(path)); ext {
 	case ".yaml", ".yml":
-		err = parseYAML(path, &data)
+		err = parseYAML(path, &root, data.positions, opts)
 	case ".json":
-		err = parseJSON(path, &data)
+		err = parseJSON(path, &root, data.positions)
 	case ".toml":
-		err = parseTOML(path, &data)
+		err = parseTOML(path, &data.values, data.positions)
 	case ".env":
 		err = parseENV(path)
 	default:
@@ -97,36 +186,91 @@ func parseFile(path string) (map[string]any, error) {
 		return nil, fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
 	}
 
-	return data, nil
-}
+	switch rootValue := root.(type) {
+	case nil:
+	case map[string]any:
+		data.values = rootValue
+	case []any:
+		data.items = rootValue
+	default:
+		return nil, fmt.Errorf("error while '%s' file parsing: the root of the file must be a map or a list", path)
+	}
 
-func parseMultipleFiles(paths []string) (map[string]any, error) {
-	data := make(map[string]any)
+	if data.values == nil {
+		data.values = make(map[string]any)
+	}
 
-	var previous string
+	return data, nil
+}
 
-	if len(paths) > 0 {
-		previous = paths[0]
-	}
+func parseMultipleFiles(paths []string, opts *options) (*configData, error) {
+	data := newConfigData(confyTag)
 
 	for _, path := range paths {
-		newData, err := parseFile(path)
+		newData, err := parseFile(path, opts)
 		if err != nil {
 			return nil, err
 		}
 
-		data, err = mergeMaps(data, newData, previous, path, "")
-		if err != nil {
+		if err := mergeData(data, newData, path, opts); err != nil {
 			return nil, err
 		}
-
-		previous = path
 	}
 
+	dropDeleteMarkers(data.values, "", data.positions)
+	dropDeleteMarkers(data.items, "", data.positions)
+
 	return data, nil
 }
 
-func parseYAML(path string, to *map[string]any) error {
+// mergeData merges the data of the file into the data of the previous files.
+func mergeData(data, newData *configData, path string, opts *options) error {
+	return mergeDataMarkers(data, newData, path, opts, false)
+}
+
+// mergeDataMarkers is like mergeData, but it can keep the delete markers of the new data
+// if the merged data is merged into the data of the previous sources later.
+func mergeDataMarkers(data, newData *configData, path string, opts *options, keepMarkers bool) error {
+	m := &merger{
+		opts:        opts,
+		dstPos:      data.positions,
+		srcPos:      newData.positions,
+		srcPath:     path,
+		keepMarkers: keepMarkers,
+	}
+
+	if newData.items != nil || data.items != nil {
+		if len(data.values) > 0 || len(newData.values) > 0 {
+			return fmt.Errorf("conflict while files read: the root of the '%s' file can't be merged with the root of the previous files, because one of them is a list and the other is a map", path)
+		}
+
+		if data.items == nil {
+			data.items = newData.items
+		} else if newData.items != nil {
+			value, err := m.mergeValues(data.items, newData.items, "")
+			if err != nil {
+				return err
+			}
+
+			data.items = value.([]any)
+		}
+	}
+
+	values, err := m.mergeMaps(data.values, newData.values, "")
+	if err != nil {
+		return err
+	}
+
+	data.values = values
+	data.positions.merge(newData.positions)
+
+	return nil
+}
+
+// parseYAML parses the documents of the file. The documents are merged in their order,
+// the later documents override the values of the earlier ones. The documents with
+// the "$profile" key are merged only if one of their profiles is active.
+func parseYAML(path string, to *any, pos positions, opts *options) error {
 	f, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
 		return err
@@ -138,10 +282,72 @@ func parseYAML(path string, to *map[string]any) error {
 		return err
 	}
 
-	return yaml.Unmarshal(b, to)
+	docOpts := *opts
+	docOpts.mergePolicy = MergeLastWins
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		var node yaml.Node
+
+		if err := dec.Decode(&node); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if node.Kind == 0 {
+			continue
+		}
+
+		resolveUnsetTags(&node)
+
+		var doc any
+
+		if err := node.Decode(&doc); err != nil {
+			return err
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		docPos := make(positions)
+
+		collectYAMLPositions(path, &node, "", docPos)
+
+		active, err := isDocumentActive(doc, docPos, opts)
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			continue
+		}
+
+		if *to == nil {
+			*to = doc
+		} else {
+			m := &merger{
+				opts:    &docOpts,
+				dstPos:  pos,
+				srcPos:  docPos,
+				srcPath: path,
+			}
+
+			*to, err = m.mergeValues(*to, doc, "")
+			if err != nil {
+				return err
+			}
+		}
+
+		pos.merge(docPos)
+	}
+
+	return nil
 }
 
-func parseJSON(path string, to *map[string]any) error {
+func parseJSON(path string, to *any, pos positions) error {
 	f, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
 		return err
@@ -153,10 +359,14 @@ func parseJSON(path string, to *map[string]any) error {
 		return err
 	}
 
-	return json.Unmarshal(b, to)
+	if err := json.Unmarshal(b, to); err != nil {
+		return err
+	}
+
+	return collectJSONPositions(path, b, pos)
 }
 
-func parseTOML(path string, to *map[string]any) error {
+func parseTOML(path string, to *map[string]any, pos positions) error {
 	f, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
 		return err
@@ -168,48 +378,40 @@ func parseTOML(path string, to *map[string]any) error {
 		return err
 	}
 
-	return toml.Unmarshal(b, to)
+	md, err := toml.Decode(string(b), to)
+	if err != nil {
+		return err
+	}
+
+	collectTOMLPositions(path, b, md, pos)
+
+	return nil
 }
 
 func parseENV(path string) error {
 	return godotenv.Load(path)
 }
 
-func mergeMaps(dst, src map[string]any, dstPath, srcPath, commonKey string) (map[string]any, error) {
-	for key, val := range src {
-		if dstVal, ok := dst[key]; ok {
-			if commonKey == "" {
-				commonKey = key
-			} else {
-				commonKey += "." + key
-			}
+// getValidFiles returns the config files of the directory in the order they are read.
+func getValidFiles(path string, opts *options) ([]string, error) {
+	if opts.manifest != "" {
+		files, ok, err := getManifestFiles(path, opts.manifest)
+		if err != nil {
+			return nil, err
+		}
 
-			if dstValMap, ok := dstVal.(map[string]any); ok {
-				if valMap, ok := val.(map[string]any); ok {
-					newVal, err := mergeMaps(dstValMap, valMap, dstPath, srcPath, commonKey)
-					if err != nil {
-						return nil, err
-					}
-
-					dst[key] = newVal
-				} else {
-					return nil, fmt.Errorf("conflict while files read: it is impossible to unambiguously determine the value for the '%s' key specified in the '%s' file and in the '%s' file", commonKey, dstPath, srcPath)
-				}
-			} else {
-				return nil, fmt.Errorf("conflict while files read: it is impossible to unambiguously determine the value for the '%s' key specified in the '%s' file and in the '%s' file", commonKey, dstPath, srcPath)
-			}
-		} else {
-			dst[key] = val
+		if ok {
+			return files, nil
 		}
 	}
 
-	return dst, nil
-}
-
-func getValidFiles(path string) ([]string, error) {
 	paths := make([]string, 0)
 
 	err := filepath.Walk(path, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
 		if !info.IsDir() {
 			ext := strings.ToLower(filepath.Ext(path))
 
@@ -224,22 +426,21 @@ func getValidFiles(path string) ([]string, error) {
 		return nil, fmt.Errorf("error while '%s' directory read: %s", path, err.Error())
 	}
 
+	sortFiles(path, paths, opts.fileOrder)
+
 	return paths, nil
 }
 
-func getAllPaths(path string) ([]string, error) {
-	paths := make([]string, 0)
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
 
-	err := filepath.Walk(path, func(path string, info fs.FileInfo, err error) error {
-		paths = append(paths, path)
+	return err == nil && !fi.IsDir()
+}
 
-		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("error while '%s' directory read: %s", path, err.Error())
-	}
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
 
-	return paths, nil
+	return err == nil && fi.IsDir()
 }
 
 func getFileTag(path string) string {
@@ -266,7 +467,7 @@ func getMultipleFilesTag(paths []string) string {
 		}
 	}
 
-	if len(exts) > 1 {
+	if len(exts) != 1 {
 		return confyTag
 	}
 
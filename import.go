@@ -0,0 +1,227 @@
+package confy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// importKey is the key of the file that lists the files imported by it.
+	importKey = "$import"
+
+	// optionalImportPrefix marks the imports that may not exist.
+	optionalImportPrefix = "?"
+)
+
+// fileParser parses the files with the files imported by them.
+type fileParser struct {
+	opts *options
+
+	// Directory that the imported files must be inside of
+	root string
+
+	// Files that are being parsed, used to detect the import cycles
+	stack []string
+}
+
+// parseFile parses the file and merges the files imported by its "$import" key
+// (a path or a list of paths relative to the file) with its data. The imports are
+// merged with each other in the listed order by the merge policy, then the values
+// of the file override the values of its imports.
+//
+// The paths can be glob patterns, the paths starting with '?' are optional.
+// All imports must be inside the directory of the file.
+func parseFile(path string, opts *options) (*configData, error) {
+	root, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
+	}
+
+	p := &fileParser{
+		opts: opts,
+		root: root,
+	}
+
+	return p.parse(path)
+}
+
+func (p *fileParser) parse(path string) (*configData, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
+	}
+
+	if i := slices.Index(p.stack, absPath); i >= 0 {
+		cycle := append(slices.Clone(p.stack[i:]), absPath)
+
+		return nil, fmt.Errorf("error while '%s' file parsing: import cycle '%s'", path, strings.Join(cycle, "' -> '"))
+	}
+
+	data, err := parseSingleFile(path, p.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parent profile is resolved by the reader
+	data.deleteKey(extendsKey)
+
+	value, ok := data.values[importKey]
+	if !ok {
+		return data, nil
+	}
+
+	data.deleteKey(importKey)
+
+	imports, err := p.resolveImports(path, value)
+	if err != nil {
+		return nil, err
+	}
+
+	p.stack = append(p.stack, absPath)
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	merged := newConfigData(data.tag)
+
+	for _, importPath := range imports {
+		importData, err := p.parse(importPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mergeData(merged, importData, importPath, p.opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// The values of the file override the values of its imports
+	ownOpts := *p.opts
+	ownOpts.mergePolicy = MergeLastWins
+
+	if err := mergeData(merged, data, path, &ownOpts); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// resolveImports returns the paths of the files imported by the file in the listed order.
+func (p *fileParser) resolveImports(path string, value any) ([]string, error) {
+	var patterns []string
+
+	switch v := value.(type) {
+	case string:
+		patterns = []string{v}
+	case []any:
+		for _, item := range v {
+			pattern, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("error while '%s' file parsing: the value of the '%s' key must be a path or a list of paths", path, importKey)
+			}
+
+			patterns = append(patterns, pattern)
+		}
+	default:
+		return nil, fmt.Errorf("error while '%s' file parsing: the value of the '%s' key must be a path or a list of paths", path, importKey)
+	}
+
+	paths := make([]string, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		pattern, optional := strings.CutPrefix(pattern, optionalImportPrefix)
+
+		fullPattern := filepath.Join(filepath.Dir(path), filepath.FromSlash(pattern))
+
+		matches, err := filepath.Glob(fullPattern)
+		if err != nil {
+			return nil, fmt.Errorf("error while '%s' file parsing: invalid '%s' import: %s", path, pattern, err.Error())
+		}
+
+		files := make([]string, 0, len(matches))
+
+		for _, match := range matches {
+			if err := p.checkImport(path, pattern, match); err != nil {
+				return nil, err
+			}
+
+			fi, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
+			}
+
+			if fi.IsDir() {
+				continue
+			}
+
+			// The glob patterns can match the files of any type
+			if match != fullPattern && !slices.Contains(validExtensions, strings.ToLower(filepath.Ext(match))) {
+				continue
+			}
+
+			files = append(files, match)
+		}
+
+		if len(files) == 0 {
+			if err := p.checkImport(path, pattern, fullPattern); err != nil {
+				return nil, err
+			}
+
+			if !optional {
+				return nil, fmt.Errorf("error while '%s' file parsing: the '%s' import wasn't found", path, pattern)
+			}
+		}
+
+		paths = append(paths, files...)
+	}
+
+	return paths, nil
+}
+
+// checkImport checks that the imported file is inside the root directory.
+// The symbolic links are resolved, so the links can't point outside the root.
+func (p *fileParser) checkImport(path, pattern, importPath string) error {
+	root, err := resolvePath(p.root)
+	if err != nil {
+		return fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
+	}
+
+	absPath, err := resolvePath(importPath)
+	if err != nil {
+		return fmt.Errorf("error while '%s' file parsing: %s", path, err.Error())
+	}
+
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("error while '%s' file parsing: the '%s' import is outside the '%s' directory", path, pattern, p.root)
+	}
+
+	return nil
+}
+
+// resolvePath returns the absolute path with the resolved symbolic links.
+// The links are resolved in the part of the path that exists.
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err == nil {
+		return resolved, nil
+	}
+
+	dir := filepath.Dir(absPath)
+	if dir == absPath {
+		return absPath, nil
+	}
+
+	resolvedDir, err := resolvePath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(resolvedDir, filepath.Base(absPath)), nil
+}
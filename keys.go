@@ -0,0 +1,74 @@
+package confy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyMatching is the policy of matching the keys of the config data with the keys of the fields.
+type KeyMatching int
+
+const (
+	// KeyMatchExact matches the keys only if they are equal.
+	KeyMatchExact KeyMatching = iota
+
+	// KeyMatchCaseInsensitive matches the keys regardless of the case, so "maxConns" matches "maxconns".
+	KeyMatchCaseInsensitive
+
+	// KeyMatchNormalized matches the keys regardless of the case and the "_" and "-" separators,
+	// so "max_conns", "max-conns" and "maxConns" match each other.
+	KeyMatchNormalized
+)
+
+// WithKeyMatching sets the policy of matching the keys of the config data with the keys of the fields.
+func WithKeyMatching(matching KeyMatching) Option {
+	return func(o *options) {
+		o.keyMatching = matching
+	}
+}
+
+// matchKey replaces the key of the field with the key of the data that matches it by the key matching policy.
+func (d *decoder) matchKey(data map[string]any, metadata, fieldMetadata *fieldMeta) error {
+	key := fieldMetadata.key
+
+	if _, ok := data[key]; ok || d.opts.keyMatching == KeyMatchExact {
+		return nil
+	}
+
+	normalizedKey := d.normalizeKey(key)
+	matched := ""
+
+	for dataKey := range data {
+		if d.normalizeKey(dataKey) != normalizedKey {
+			continue
+		}
+
+		if matched != "" {
+			if matched > dataKey {
+				matched, dataKey = dataKey, matched
+			}
+
+			return d.fieldError(fieldMetadata, false, fmt.Errorf("error while value parsing: the '%s' and '%s' keys both match the '%s' field", joinPath(metadata.path, matched), joinPath(metadata.path, dataKey), fieldMetadata.name))
+		}
+
+		matched = dataKey
+	}
+
+	if matched != "" {
+		fieldMetadata.key = matched
+		fieldMetadata.path = joinPath(metadata.path, matched)
+	}
+
+	return nil
+}
+
+func (d *decoder) normalizeKey(key string) string {
+	switch d.opts.keyMatching {
+	case KeyMatchCaseInsensitive:
+		return strings.ToLower(key)
+	case KeyMatchNormalized:
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	default:
+		return key
+	}
+}
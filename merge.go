@@ -0,0 +1,342 @@
+package confy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergePolicy is the policy of resolving the conflicts of the values
+// set by several files for the same key.
+type MergePolicy int
+
+const (
+	// MergeConflictError returns an error if several files set the same key.
+	MergeConflictError MergePolicy = iota
+
+	// MergeLastWins uses the value of the last file.
+	MergeLastWins
+
+	// MergeFirstWins uses the value of the first file.
+	MergeFirstWins
+)
+
+// ListStrategy is the strategy of merging the lists set by several files for the same key.
+type ListStrategy string
+
+const (
+	// ListReplace uses the list of the last file.
+	ListReplace ListStrategy = "replace"
+
+	// ListAppend concatenates the lists in the order of the files.
+	ListAppend ListStrategy = "append"
+)
+
+const listMergeByKeyPrefix = "merge-by-key:"
+
+const (
+	// deleteMarker is the value that removes the key set by the previous files,
+	// so the field gets its default or zero value.
+	deleteMarker = "~delete"
+
+	// unsetTag is the YAML tag that is the same as the delete marker.
+	unsetTag = "!unset"
+)
+
+// ListMergeByKey merges the map items of the lists that have the same value of the key
+// (for example "name") by the merge policy. The other items are appended.
+func ListMergeByKey(key string) ListStrategy {
+	return ListStrategy(listMergeByKeyPrefix + key)
+}
+
+// WithMergePolicy sets the policy of resolving the conflicts of the values
+// set by several files for the same key. By default, an error is returned.
+func WithMergePolicy(policy MergePolicy) Option {
+	return func(o *options) {
+		o.mergePolicy = policy
+	}
+}
+
+// WithListStrategy sets the strategy of merging the lists at the data path (for example "servers").
+// The lists without the strategy are merged by the merge policy.
+func WithListStrategy(path string, strategy ListStrategy) Option {
+	return func(o *options) {
+		if o.listStrategies == nil {
+			o.listStrategies = make(map[string]ListStrategy)
+		}
+
+		o.listStrategies[path] = strategy
+	}
+}
+
+// merger merges the data of the file into the data of the previous files.
+type merger struct {
+	opts    *options
+	dstPos  positions
+	srcPos  positions
+	srcPath string
+
+	// The delete markers are kept if the merged data is merged
+	// into the data of the previous sources later
+	keepMarkers bool
+}
+
+func (m *merger) mergeMaps(dst, src map[string]any, commonKey string) (map[string]any, error) {
+	for _, key := range slices.Sorted(maps.Keys(src)) {
+		val := src[key]
+		keyPath := joinPath(commonKey, key)
+
+		if isDeleteMarker(val) {
+			if m.keepMarkers {
+				dst[key] = val
+			} else {
+				delete(dst, key)
+			}
+
+			m.deletePositions(keyPath)
+
+			continue
+		}
+
+		// The kept marker deletes only the values of the previous sources
+		if dstVal, ok := dst[key]; ok && !isDeleteMarker(dstVal) {
+			newVal, err := m.mergeValues(dstVal, val, keyPath)
+			if err != nil {
+				return nil, err
+			}
+
+			dst[key] = newVal
+		} else {
+			// The markers without the values to delete do nothing
+			m.dropDeleteMarkers(val, keyPath)
+
+			dst[key] = val
+		}
+	}
+
+	return dst, nil
+}
+
+func (m *merger) mergeValues(dst, src any, path string) (any, error) {
+	if dstMap, ok := dst.(map[string]any); ok {
+		if srcMap, ok := src.(map[string]any); ok {
+			return m.mergeMaps(dstMap, srcMap, path)
+		}
+	}
+
+	if dstList, ok := dst.([]any); ok {
+		if srcList, ok := src.([]any); ok {
+			if strategy, ok := m.opts.listStrategies[path]; ok {
+				return m.mergeLists(dstList, srcList, path, strategy)
+			}
+		}
+	}
+
+	switch m.opts.mergePolicy {
+	case MergeLastWins:
+		m.dropDeleteMarkers(src, path)
+		m.replacePositions(path, path)
+
+		return src, nil
+
+	case MergeFirstWins:
+		return dst, nil
+
+	default:
+		return nil, fmt.Errorf("conflict while files read: it is impossible to unambiguously determine the value for the '%s' key specified in '%s' and in '%s'", path, m.dstPos.locate(path, "another file"), m.srcPos.locate(path, m.srcPath))
+	}
+}
+
+func (m *merger) mergeLists(dst, src []any, path string, strategy ListStrategy) ([]any, error) {
+	switch {
+	case strategy == ListReplace:
+		m.dropDeleteMarkers(src, path)
+		m.replacePositions(path, path)
+
+		return src, nil
+
+	case strategy == ListAppend:
+		m.dropDeleteMarkers(src, path)
+
+		for i := range src {
+			m.replacePositions(indexPath(path, len(dst)+i), indexPath(path, i))
+		}
+
+		return append(dst, src...), nil
+
+	case strings.HasPrefix(string(strategy), listMergeByKeyPrefix):
+		key := strings.TrimPrefix(string(strategy), listMergeByKeyPrefix)
+
+		indexes := make(map[string]int)
+
+		for i, item := range dst {
+			if itemMap, ok := item.(map[string]any); ok {
+				if value, ok := itemMap[key]; ok {
+					indexes[fmt.Sprint(value)] = i
+				}
+			}
+		}
+
+		for i, item := range src {
+			itemMap, ok := item.(map[string]any)
+			if ok {
+				if value, ok := itemMap[key]; ok {
+					if dstIndex, ok := indexes[fmt.Sprint(value)]; ok {
+						merged, err := m.mergeItem(dst[dstIndex].(map[string]any), itemMap, indexPath(path, dstIndex), indexPath(path, i))
+						if err != nil {
+							return nil, err
+						}
+
+						dst[dstIndex] = merged
+
+						continue
+					}
+				}
+			}
+
+			m.dropDeleteMarkers(item, indexPath(path, i))
+			m.replacePositions(indexPath(path, len(dst)), indexPath(path, i))
+
+			dst = append(dst, item)
+		}
+
+		return dst, nil
+
+	default:
+		return nil, fmt.Errorf("confy doesn`t support '%s' list strategy", strategy)
+	}
+}
+
+// mergeItem merges the list items, whose paths in the dst and src data are different.
+func (m *merger) mergeItem(dst, src map[string]any, dstPath, srcPath string) (map[string]any, error) {
+	itemPos := make(positions)
+
+	for p, pos := range m.srcPos {
+		if rest, ok := cutPathPrefix(p, srcPath); ok {
+			itemPos[dstPath+rest] = pos
+		}
+	}
+
+	itemMerger := &merger{
+		opts:        m.opts,
+		dstPos:      m.dstPos,
+		srcPos:      itemPos,
+		srcPath:     m.srcPath,
+		keepMarkers: m.keepMarkers,
+	}
+
+	merged, err := itemMerger.mergeMaps(dst, src, dstPath)
+	if err != nil {
+		return nil, err
+	}
+
+	m.dstPos.merge(itemPos)
+
+	return merged, nil
+}
+
+// replacePositions replaces the positions of the dst path and its children
+// with the positions of the src path of the merged file.
+func (m *merger) replacePositions(dstPath, srcPath string) {
+	for p := range m.dstPos {
+		if _, ok := cutPathPrefix(p, dstPath); ok {
+			delete(m.dstPos, p)
+		}
+	}
+
+	for p, pos := range m.srcPos {
+		if rest, ok := cutPathPrefix(p, srcPath); ok {
+			m.dstPos[dstPath+rest] = pos
+		}
+	}
+}
+
+// deletePositions deletes the positions of the path and its children from the dst and src data.
+func (m *merger) deletePositions(path string) {
+	for _, pos := range []positions{m.dstPos, m.srcPos} {
+		for p := range pos {
+			if _, ok := cutPathPrefix(p, path); ok {
+				delete(pos, p)
+			}
+		}
+	}
+}
+
+// dropDeleteMarkers removes the delete markers from the value of the merged data,
+// unless they are kept for the data of the previous sources.
+func (m *merger) dropDeleteMarkers(value any, path string) {
+	if !m.keepMarkers {
+		dropDeleteMarkers(value, path, m.srcPos)
+	}
+}
+
+func isDeleteMarker(value any) bool {
+	str, ok := value.(string)
+
+	return ok && str == deleteMarker
+}
+
+// dropDeleteMarkers removes the keys with the delete markers from the value
+// that is not merged with the previous data, so the markers do nothing.
+func dropDeleteMarkers(value any, path string, pos positions) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			keyPath := joinPath(path, key)
+
+			if isDeleteMarker(val) {
+				delete(v, key)
+
+				for p := range pos {
+					if _, ok := cutPathPrefix(p, keyPath); ok {
+						delete(pos, p)
+					}
+				}
+
+				continue
+			}
+
+			dropDeleteMarkers(val, keyPath, pos)
+		}
+
+	case []any:
+		for i, item := range v {
+			dropDeleteMarkers(item, indexPath(path, i), pos)
+		}
+	}
+}
+
+// resolveUnsetTags replaces the YAML nodes with the unset tag by the delete markers.
+func resolveUnsetTags(node *yaml.Node) {
+	if node.Tag == unsetTag {
+		node.Kind = yaml.ScalarNode
+		node.Tag = "!!str"
+		node.Value = deleteMarker
+		node.Style = 0
+		node.Content = nil
+
+		return
+	}
+
+	for _, content := range node.Content {
+		resolveUnsetTags(content)
+	}
+}
+
+// cutPathPrefix returns the rest of the path after the prefix,
+// if the path is equal to the prefix or is a path of its child.
+func cutPathPrefix(path, prefix string) (string, bool) {
+	if prefix == "" {
+		return path, true
+	}
+
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || rest != "" && rest[0] != '.' && rest[0] != '[' {
+		return "", false
+	}
+
+	return rest, true
+}
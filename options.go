@@ -0,0 +1,37 @@
+package confy
+
+// Option configures the reading of the config.
+type Option func(*options)
+
+type options struct {
+	strict      bool
+	report      *Report
+	keyMatching KeyMatching
+
+	mergePolicy    MergePolicy
+	listStrategies map[string]ListStrategy
+
+	fileOrder FileOrder
+	manifest  string
+
+	// Profiles that activate the profile sections of the files
+	profiles []string
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Strict makes reading fail if the config sources contain keys
+// that don't correspond to any field of the config struct.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
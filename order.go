@@ -0,0 +1,163 @@
+package confy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileOrder is the order in which the files of the directory sources are read and merged.
+type FileOrder int
+
+const (
+	// OrderLexical reads the files in the lexical order of their paths
+	// relative to the directory, comparing them by the path elements.
+	OrderLexical FileOrder = iota
+
+	// OrderNumericPrefix reads the files in the order of the numeric prefixes of their names
+	// (for example "2-base.yaml" before "10-override.yaml"). The names without the prefix
+	// are read after the numbered ones in the lexical order.
+	OrderNumericPrefix
+)
+
+// WithFileOrder sets the order in which the files of the directory sources are read.
+// By default, the files are read in the lexical order.
+func WithFileOrder(order FileOrder) Option {
+	return func(o *options) {
+		o.fileOrder = order
+	}
+}
+
+// WithManifest sets the name of the manifest file of the directory sources.
+// If the directory contains the manifest, only the files listed in it are read,
+// in the listed order, and the file order is ignored.
+//
+// The manifest lists one file path relative to the directory per line.
+// The empty lines and the lines starting with '#' are ignored.
+func WithManifest(name string) Option {
+	return func(o *options) {
+		o.manifest = name
+	}
+}
+
+// sortFiles sorts the files of the directory by the file order.
+// The files are walked in the lexical order already.
+func sortFiles(dir string, files []string, order FileOrder) {
+	if order == OrderNumericPrefix {
+		slices.SortStableFunc(files, func(a, b string) int {
+			return compareFilePaths(dir, a, b)
+		})
+	}
+}
+
+// compareFilePaths compares the paths relative to the directory element by element.
+func compareFilePaths(dir, a, b string) int {
+	relA, _ := filepath.Rel(dir, a)
+	relB, _ := filepath.Rel(dir, b)
+
+	partsA := strings.Split(relA, string(filepath.Separator))
+	partsB := strings.Split(relB, string(filepath.Separator))
+
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if c := compareNumericPrefix(partsA[i], partsB[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(partsA) - len(partsB)
+}
+
+func compareNumericPrefix(a, b string) int {
+	numA, okA := getNumericPrefix(a)
+	numB, okB := getNumericPrefix(b)
+
+	switch {
+	case okA && okB && numA != numB:
+		if numA < numB {
+			return -1
+		}
+
+		return 1
+	case okA && !okB:
+		return -1
+	case !okA && okB:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func getNumericPrefix(name string) (uint64, bool) {
+	end := 0
+
+	for end < len(name) && name[end] >= '0' && name[end] <= '9' {
+		end++
+	}
+
+	if end == 0 {
+		return 0, false
+	}
+
+	num, err := strconv.ParseUint(name[:end], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
+
+// getManifestFiles returns the files listed in the manifest of the directory.
+// The second value is false if the directory has no manifest.
+func getManifestFiles(dir, manifest string) ([]string, bool, error) {
+	manifestPath := filepath.Join(dir, manifest)
+
+	f, err := os.Open(manifestPath)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("error while '%s' manifest read: %s", manifestPath, err.Error())
+	}
+	defer f.Close()
+
+	files := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+
+	for line := 1; scanner.Scan(); line++ {
+		name := strings.TrimSpace(scanner.Text())
+
+		if name == "" || name[0] == '#' {
+			continue
+		}
+
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return nil, false, fmt.Errorf("error while '%s:%d' manifest read: the '%s' path must be inside the directory", manifestPath, line, name)
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("error while '%s:%d' manifest read: %s", manifestPath, line, err.Error())
+		}
+
+		if fi.IsDir() {
+			return nil, false, fmt.Errorf("error while '%s:%d' manifest read: the '%s' path is a directory", manifestPath, line, name)
+		}
+
+		if slices.Contains(files, path) {
+			return nil, false, fmt.Errorf("error while '%s:%d' manifest read: the '%s' file is listed twice", manifestPath, line, name)
+		}
+
+		files = append(files, path)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("error while '%s' manifest read: %s", manifestPath, err.Error())
+	}
+
+	return files, true, nil
+}
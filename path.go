@@ -0,0 +1,137 @@
+package confy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a map key or a list index of the data path.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// splitPath splits the data path (for example "db.hosts[0].name") into segments.
+func splitPath(path string) ([]pathSegment, error) {
+	segments := make([]pathSegment, 0)
+
+	if path == "" {
+		return segments, nil
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+
+		if key == "" && (len(segments) > 0 || rest == "") {
+			return nil, fmt.Errorf("invalid path '%s': empty key", path)
+		}
+
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid path '%s': unclosed index", path)
+			}
+
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid path '%s': invalid index '%s'", path, index)
+			}
+
+			segments = append(segments, pathSegment{index: i, isIndex: true})
+
+			if after == "" {
+				break
+			}
+
+			if after[0] != '[' {
+				return nil, fmt.Errorf("invalid path '%s': unexpected '%s' after index", path, after)
+			}
+
+			rest = after[1:]
+		}
+	}
+
+	return segments, nil
+}
+
+// lookupPath returns the value at the path of the data. The second result is false
+// if the value is not set. The error is returned if the path can't be
+// followed, for example if it contains a key of the scalar value.
+func lookupPath(data any, path string) (any, bool, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := data
+	currentPath := ""
+
+	for _, segment := range segments {
+		if segment.isIndex {
+			list, ok := current.([]any)
+			if !ok {
+				return nil, false, fmt.Errorf("the value of the '%s' key is not a list", pathName(currentPath))
+			}
+
+			currentPath = indexPath(currentPath, segment.index)
+
+			if segment.index >= len(list) {
+				return nil, false, nil
+			}
+
+			current = list[segment.index]
+		} else {
+			m, ok := current.(map[string]any)
+			if !ok {
+				return nil, false, fmt.Errorf("the value of the '%s' key is not a map", pathName(currentPath))
+			}
+
+			currentPath = joinPath(currentPath, segment.key)
+
+			current, ok = m[segment.key]
+			if !ok {
+				return nil, false, nil
+			}
+		}
+	}
+
+	return current, true, nil
+}
+
+// lookup returns the value at the path of the config data.
+// The second value is false if the value is not set.
+func (c *configData) lookup(path string) (any, bool, error) {
+	if path == "" {
+		if c.items != nil {
+			return c.items, true, nil
+		}
+
+		if len(c.values) == 0 {
+			return nil, true, nil
+		}
+
+		return c.values, true, nil
+	}
+
+	var root any = c.values
+
+	if c.items != nil {
+		root = c.items
+	}
+
+	return lookupPath(root, path)
+}
+
+func pathName(path string) string {
+	if path == "" {
+		return "root"
+	}
+
+	return path
+}
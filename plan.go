@@ -0,0 +1,203 @@
+package confy
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// fieldMeta describes the value being decoded or dumped: the config root,
+// the struct field or the element of the map, array or slice field.
+type fieldMeta struct {
+	dataTag string
+	name    string
+	key     string
+	path    string
+
+	inline    bool
+	skip      bool
+	omitEmpty bool
+	required  bool
+	secret    bool
+	immutable bool
+	separator string
+
+	env          string
+	hasEnv       bool
+	defaultValue string
+	hasDefault   bool
+	layout       string
+	hasLayout    bool
+
+	// Source of the value, set by the decoder
+	isValueEnv     bool
+	isValueDefault bool
+}
+
+// newRootMeta returns the metadata of the config root.
+func newRootMeta(name, path, dataTag string) *fieldMeta {
+	return &fieldMeta{
+		dataTag:   dataTag,
+		name:      name,
+		path:      path,
+		separator: defaultSeparator,
+	}
+}
+
+// elementMeta returns the metadata of the element of the map, array or slice field.
+func (m *fieldMeta) elementMeta(key, path string) *fieldMeta {
+	elementMetadata := *m
+
+	if len(key) > 0 && key[0] == '[' {
+		elementMetadata.name = m.name + key
+	} else {
+		elementMetadata.name = m.name + "[" + key + "]"
+	}
+
+	elementMetadata.path = path
+
+	return &elementMetadata
+}
+
+// structPlan is the decoding plan of the struct type, computed once
+// for every struct type and data tag.
+type structPlan struct {
+	fields []fieldPlan
+
+	// Key used by several fields, including the fields of the inlined structs
+	duplicate *duplicateKey
+}
+
+// fieldPlan is the metadata of the struct field that doesn't depend on its parents.
+type fieldPlan struct {
+	index    int
+	name     string
+	exported bool
+
+	// Metadata without the data tag, the name and the path
+	meta fieldMeta
+}
+
+type duplicateKey struct {
+	key    string
+	first  string
+	second string
+}
+
+type planKey struct {
+	t       reflect.Type
+	dataTag string
+}
+
+// plans caches the struct plans by their plan keys.
+var plans sync.Map
+
+func getStructPlan(t reflect.Type, dataTag string) *structPlan {
+	key := planKey{t: t, dataTag: dataTag}
+
+	if plan, ok := plans.Load(key); ok {
+		return plan.(*structPlan)
+	}
+
+	plan, _ := plans.LoadOrStore(key, newStructPlan(t, dataTag))
+
+	return plan.(*structPlan)
+}
+
+func newStructPlan(t reflect.Type, dataTag string) *structPlan {
+	plan := &structPlan{
+		fields: make([]fieldPlan, t.NumField()),
+	}
+
+	for i := range t.NumField() {
+		plan.fields[i] = newFieldPlan(i, t.Field(i), dataTag)
+	}
+
+	plan.duplicate = findDuplicateKey(t, dataTag, "", make(map[string]string), []reflect.Type{t})
+
+	return plan
+}
+
+func newFieldPlan(index int, fieldStructType reflect.StructField, dataTag string) fieldPlan {
+	meta := fieldMeta{
+		key:       getMetadataKey(fieldStructType, dataTag),
+		inline:    getMetadataInline(fieldStructType, dataTag),
+		skip:      getMetadataSkip(fieldStructType, dataTag),
+		omitEmpty: getMetadataOmitEmpty(fieldStructType, dataTag),
+		required:  getMetadataRequired(fieldStructType),
+		separator: getMetadataSeparator(fieldStructType),
+		secret:    getMetadataSecret(fieldStructType),
+		immutable: getMetadataImmutable(fieldStructType),
+	}
+
+	meta.env, meta.hasEnv = getMetadataEnv(fieldStructType)
+	meta.defaultValue, meta.hasDefault = getMetadataDefaultValue(fieldStructType)
+	meta.layout, meta.hasLayout = getMetadataLayout(fieldStructType)
+
+	return fieldPlan{
+		index:    index,
+		name:     fieldStructType.Name,
+		exported: fieldStructType.IsExported(),
+		meta:     meta,
+	}
+}
+
+// fieldMeta returns the metadata of the field of the struct with the parent metadata.
+func (p *fieldPlan) fieldMeta(parent *fieldMeta) *fieldMeta {
+	meta := p.meta
+
+	meta.dataTag = parent.dataTag
+	meta.name = parent.name + "." + p.name
+
+	if meta.inline {
+		meta.path = parent.path
+	} else {
+		meta.path = joinPath(parent.path, meta.key)
+	}
+
+	return &meta
+}
+
+// findDuplicateKey returns the key used by several fields of the struct.
+// The names of the fields are relative to the struct. The inlined types
+// are the types of the struct and its inlined structs, that are skipped if embedded again.
+func findDuplicateKey(t reflect.Type, dataTag, prefix string, keys map[string]string, inlineTypes []reflect.Type) *duplicateKey {
+	for i := range t.NumField() {
+		fieldStructType := t.Field(i)
+		field := newFieldPlan(i, fieldStructType, dataTag)
+		name := prefix + field.name
+
+		if field.meta.skip {
+			continue
+		}
+
+		if field.meta.inline {
+			fieldType := fieldStructType.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if slices.Contains(inlineTypes, fieldType) {
+				continue
+			}
+
+			if duplicate := findDuplicateKey(fieldType, dataTag, name+".", keys, append(slices.Clip(inlineTypes), fieldType)); duplicate != nil {
+				return duplicate
+			}
+
+			continue
+		}
+
+		if !field.exported {
+			continue
+		}
+
+		if first, ok := keys[field.meta.key]; ok {
+			return &duplicateKey{key: field.meta.key, first: first, second: name}
+		}
+
+		keys[field.meta.key] = name
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package confy
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type benchConfig struct {
+	Name    string        `confy:"name"`
+	Timeout time.Duration `confy:"timeout" default:"5s"`
+
+	Db struct {
+		Host     string `confy:"host"`
+		Port     int    `confy:"port"`
+		Password string `confy:"password" secret:"true"`
+	} `confy:"db"`
+
+	Servers []struct {
+		Name  string   `confy:"name"`
+		Hosts []string `confy:"hosts"`
+	} `confy:"servers"`
+
+	Labels map[string]string `confy:"labels"`
+}
+
+const benchConfigContent = `name: app
+db:
+  host: localhost
+  port: 5432
+  password: secret
+servers:
+  - name: a
+    hosts: [a1, a2]
+  - name: b
+    hosts: [b1]
+labels:
+  team: core
+  tier: backend
+`
+
+func BenchmarkRead(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "config.yaml")
+	writeBenchFile(b, path, benchConfigContent)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var cfg benchConfig
+
+		if err := Read(&cfg, path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFillConfig(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "config.yaml")
+	writeBenchFile(b, path, benchConfigContent)
+
+	opts := newOptions()
+
+	data, err := getFileData(path, opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var cfg benchConfig
+
+		if err := fillConfig(&cfg, data, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStructPlan(b *testing.B) {
+	t := reflect.TypeFor[benchConfig]()
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for b.Loop() {
+			getStructPlan(t, yamlTag)
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for b.Loop() {
+			newStructPlan(t, yamlTag)
+		}
+	})
+}
@@ -0,0 +1,411 @@
+package confy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// position is the place in a source file where a value was defined.
+type position struct {
+	file   string
+	line   int
+	column int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.file, p.line, p.column)
+}
+
+// positions maps data paths (for example "db.hosts[0]") to their positions.
+type positions map[string]position
+
+func (p positions) locate(path, file string) string {
+	if pos, ok := p[path]; ok {
+		return pos.String()
+	}
+
+	return file
+}
+
+// merge adds the positions of the paths that are not defined yet.
+func (p positions) merge(src positions) {
+	for path, pos := range src {
+		if _, ok := p[path]; !ok {
+			p[path] = pos
+		}
+	}
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" || key == "" || strings.HasPrefix(key, "[") {
+		return parent + key
+	}
+
+	return parent + "." + key
+}
+
+func indexPath(parent string, index int) string {
+	return parent + "[" + strconv.Itoa(index) + "]"
+}
+
+func collectYAMLPositions(file string, node *yaml.Node, path string, to positions) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			collectYAMLPositions(file, content, path, to)
+		}
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			if key.Value == "<<" {
+				continue
+			}
+
+			keyPath := joinPath(path, key.Value)
+
+			to[keyPath] = position{file: file, line: value.Line, column: value.Column}
+
+			collectYAMLPositions(file, value, keyPath, to)
+		}
+
+	case yaml.SequenceNode:
+		for i, value := range node.Content {
+			itemPath := indexPath(path, i)
+
+			to[itemPath] = position{file: file, line: value.Line, column: value.Column}
+
+			collectYAMLPositions(file, value, itemPath, to)
+		}
+	}
+}
+
+func collectJSONPositions(file string, b []byte, to positions) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	lines := newLineIndex(b)
+
+	return walkJSONValue(dec, b, lines, file, "", to)
+}
+
+func walkJSONValue(dec *json.Decoder, b []byte, lines lineIndex, file, path string, to positions) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			key, ok := token.(string)
+			if !ok {
+				return fmt.Errorf("unexpected object key %v", token)
+			}
+
+			keyPath := joinPath(path, key)
+
+			to[keyPath] = lines.position(file, jsonTokenStart(b, dec.InputOffset()))
+
+			if err := walkJSONValue(dec, b, lines, file, keyPath, to); err != nil {
+				return err
+			}
+		}
+
+	case '[':
+		for i := 0; dec.More(); i++ {
+			itemPath := indexPath(path, i)
+
+			to[itemPath] = lines.position(file, jsonTokenStart(b, dec.InputOffset()))
+
+			if err := walkJSONValue(dec, b, lines, file, itemPath, to); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Read the closing delimiter
+	_, err = dec.Token()
+
+	return err
+}
+
+// jsonTokenStart skips the separators between the decoder offset and the next token.
+func jsonTokenStart(b []byte, offset int64) int {
+	i := int(offset)
+
+	for i < len(b) {
+		switch b[i] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			i++
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+func collectTOMLPositions(file string, b []byte, md toml.MetaData, to positions) {
+	var (
+		table       []string
+		arrayTables = make(map[string]int)
+		depth       int
+		multiline   string
+	)
+
+	for i, line := range strings.Split(string(b), "\n") {
+		trimmed := strings.TrimSpace(line)
+		column := strings.Index(line, trimmed) + 1
+
+		if multiline != "" {
+			if strings.Count(trimmed, multiline)%2 == 1 {
+				multiline = ""
+			}
+
+			continue
+		}
+
+		if depth > 0 {
+			depth += tomlBracketDepth(trimmed)
+
+			continue
+		}
+
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+
+		pos := position{file: file, line: i + 1, column: column}
+
+		if strings.HasPrefix(trimmed, "[[") {
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				continue
+			}
+
+			table = splitTOMLKey(trimmed[2:end])
+			name := strings.Join(table, ".")
+			arrayTables[name]++
+
+			to[tomlPath(table, arrayTables)] = pos
+
+			continue
+		}
+
+		if trimmed[0] == '[' {
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+
+			table = splitTOMLKey(trimmed[1:end])
+
+			if md.IsDefined(table...) {
+				to[tomlPath(table, arrayTables)] = pos
+			}
+
+			continue
+		}
+
+		eq := strings.Index(trimmed, "=")
+		if eq < 0 {
+			continue
+		}
+
+		key := append(append([]string{}, table...), splitTOMLKey(trimmed[:eq])...)
+		value := strings.TrimSpace(trimmed[eq+1:])
+
+		if md.IsDefined(key...) {
+			pos.column += strings.Index(trimmed[eq:], value) + eq
+
+			to[tomlPath(key, arrayTables)] = pos
+
+			// The keys of the inline tables are on the same line
+			if value != "" && (value[0] == '{' || value[0] == '[') {
+				collectTOMLValuePositions(file, line, i+1, pos.column-1, tomlPath(key, arrayTables), to)
+			}
+		}
+
+		for _, quote := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(value, quote) && strings.Count(value, quote) == 1 {
+				multiline = quote
+			}
+		}
+
+		depth = tomlBracketDepth(value)
+	}
+}
+
+// tomlPath converts a TOML key into a data path, indexing the latest
+// element of every array of tables the key belongs to.
+func tomlPath(key []string, arrayTables map[string]int) string {
+	path := ""
+
+	for i, part := range key {
+		path = joinPath(path, part)
+
+		if count, ok := arrayTables[strings.Join(key[:i+1], ".")]; ok {
+			path = indexPath(path, count-1)
+		}
+	}
+
+	return path
+}
+
+func splitTOMLKey(key string) []string {
+	parts := strings.Split(key, ".")
+
+	for i := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(parts[i]), `"'`)
+	}
+
+	return parts
+}
+
+// collectTOMLValuePositions records the positions of the keys of the inline tables
+// and the items of the arrays of the value at the start index of the line.
+// It returns the index after the value.
+func collectTOMLValuePositions(file, line string, lineNum, start int, path string, to positions) int {
+	if start >= len(line) {
+		return start
+	}
+
+	switch line[start] {
+	case '{', '[':
+		isTable := line[start] == '{'
+		index := 0
+
+		for i := start + 1; i < len(line); {
+			i = skipTOMLSpaces(line, i)
+			if i >= len(line) || line[i] == '#' {
+				return len(line)
+			}
+
+			switch line[i] {
+			case '}', ']':
+				return i + 1
+			case ',':
+				i++
+
+				continue
+			}
+
+			itemPath := indexPath(path, index)
+
+			if isTable {
+				eq := strings.IndexByte(line[i:], '=')
+				if eq < 0 {
+					return len(line)
+				}
+
+				itemPath = path
+
+				for _, part := range splitTOMLKey(line[i : i+eq]) {
+					itemPath = joinPath(itemPath, part)
+				}
+
+				i = skipTOMLSpaces(line, i+eq+1)
+			}
+
+			to[itemPath] = position{file: file, line: lineNum, column: i + 1}
+
+			i = collectTOMLValuePositions(file, line, lineNum, i, itemPath, to)
+			index++
+		}
+
+		return len(line)
+
+	case '"', '\'':
+		quote := line[start]
+
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' && quote == '"' {
+				i++
+			} else if line[i] == quote {
+				return i + 1
+			}
+		}
+
+		return len(line)
+
+	default:
+		end := strings.IndexAny(line[start:], ",]} \t#")
+		if end < 0 {
+			return len(line)
+		}
+
+		return start + end
+	}
+}
+
+func skipTOMLSpaces(line string, i int) int {
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+
+	return i
+}
+
+func tomlBracketDepth(value string) int {
+	depth := 0
+	quote := byte(0)
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+
+	return depth
+}
+
+// lineIndex holds the offsets of line beginnings of a file.
+type lineIndex []int
+
+func newLineIndex(b []byte) lineIndex {
+	lines := lineIndex{0}
+
+	for i, c := range b {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+
+	return lines
+}
+
+func (l lineIndex) position(file string, offset int) position {
+	line := sort.Search(len(l), func(i int) bool { return l[i] > offset }) - 1
+
+	return position{file: file, line: line + 1, column: offset - l[line] + 1}
+}
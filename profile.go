@@ -0,0 +1,206 @@
+package confy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultProfile = "local"
+
+	// profileSeparator separates the active profiles in the environment variable.
+	profileSeparator = ","
+
+	// extendsKey is the key of the profile file that declares the parent profile.
+	extendsKey = "$extends"
+
+	// profileKey is the key of the YAML document that is merged only for its profiles.
+	profileKey = "$profile"
+)
+
+// WithProfiles activates the documents of the YAML files that have
+// one of the profiles in their "$profile" key. The Reader activates
+// the documents of its resolved profiles.
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = profiles
+	}
+}
+
+// Profiles returns the resolved chain of the active profiles in the order their
+// sources are read. The active profiles are listed in the environment variable of
+// the reader separated by commas (for example "prod,eu-west,canary"), the default
+// profile ("local" by default) is used if the variable is not set.
+//
+// A profile can declare its parent with the "$extends" key of its file, so it contains
+// only the differences from the parent. The parents are read before their children,
+// each profile is read once. The values of every profile override the values of the
+// shared sources and the earlier profiles by any merge policy, the merge policy
+// applies to the files of the same profile.
+func (r *reader) Profiles() ([]string, error) {
+	chain := make([]string, 0)
+
+	for _, profile := range r.getActiveProfiles() {
+		if err := r.addProfile(profile, nil, &chain); err != nil {
+			return nil, err
+		}
+	}
+
+	return chain, nil
+}
+
+func (r *reader) getActiveProfiles() []string {
+	env, ok := os.LookupEnv(r.envVarName)
+	if !ok {
+		return []string{r.defaultProfile}
+	}
+
+	profiles := make([]string, 0)
+
+	for _, profile := range strings.Split(env, profileSeparator) {
+		profile = strings.TrimSpace(profile)
+
+		if profile != "" && !slices.Contains(profiles, profile) {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	if len(profiles) == 0 {
+		return []string{r.defaultProfile}
+	}
+
+	return profiles
+}
+
+// addProfile adds the profile to the chain after its parents.
+// The stack holds the children of the profile to detect the cycles.
+func (r *reader) addProfile(profile string, stack []string, chain *[]string) error {
+	if slices.Contains(*chain, profile) {
+		return nil
+	}
+
+	if i := slices.Index(stack, profile); i >= 0 {
+		cycle := append(slices.Clone(stack[i:]), profile)
+
+		return fmt.Errorf("confy: profile inheritance cycle '%s'", strings.Join(cycle, "' -> '"))
+	}
+
+	parent, err := r.getProfileParent(profile)
+	if err != nil {
+		return err
+	}
+
+	if parent != "" {
+		if err := r.addProfile(parent, append(stack, profile), chain); err != nil {
+			return err
+		}
+	}
+
+	*chain = append(*chain, profile)
+
+	return nil
+}
+
+// getProfileParent returns the parent profile declared in the files of the profile.
+func (r *reader) getProfileParent(profile string) (string, error) {
+	sources, err := r.resolveProfile(profile)
+	if err != nil {
+		return "", err
+	}
+
+	parent := ""
+	parentFile := ""
+
+	for _, source := range sources {
+		files := []string{source}
+
+		if fi, err := os.Stat(source); err == nil && fi.IsDir() {
+			files, err = getValidFiles(source, r.options)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		for _, file := range files {
+			// The env files have no keys and are loaded on parsing
+			if strings.ToLower(filepath.Ext(file)) == ".env" {
+				continue
+			}
+
+			data, err := r.parseCached(file, r.options)
+			if err != nil {
+				return "", err
+			}
+
+			value, ok := data.values[extendsKey]
+			if !ok {
+				continue
+			}
+
+			name, ok := value.(string)
+			if !ok || name == "" {
+				return "", fmt.Errorf("confy: the value of the '%s' key in '%s' must be a profile name", extendsKey, data.positions.locate(extendsKey, file))
+			}
+
+			if parent != "" && parent != name {
+				return "", fmt.Errorf("confy: the '%s' profile extends the '%s' profile in '%s' and the '%s' profile in '%s'", profile, parent, parentFile, name, file)
+			}
+
+			parent = name
+			parentFile = file
+		}
+	}
+
+	return parent, nil
+}
+
+// isDocumentActive checks whether the document has no profiles or one of them is active.
+// The "$profile" key is a profile name, a comma-separated list or a list of names.
+func isDocumentActive(doc any, pos positions, opts *options) (bool, error) {
+	docMap, ok := doc.(map[string]any)
+	if !ok {
+		return true, nil
+	}
+
+	value, ok := docMap[profileKey]
+	if !ok {
+		return true, nil
+	}
+
+	var profiles []string
+
+	switch v := value.(type) {
+	case string:
+		profiles = strings.Split(v, profileSeparator)
+	case []any:
+		for _, item := range v {
+			profile, ok := item.(string)
+			if !ok {
+				return false, fmt.Errorf("the value of the '%s' key in '%s' must be a profile name or a list of names", profileKey, pos.locate(profileKey, "the document"))
+			}
+
+			profiles = append(profiles, profile)
+		}
+	default:
+		return false, fmt.Errorf("the value of the '%s' key in '%s' must be a profile name or a list of names", profileKey, pos.locate(profileKey, "the document"))
+	}
+
+	delete(docMap, profileKey)
+
+	for path := range pos {
+		if _, ok := cutPathPrefix(path, profileKey); ok {
+			delete(pos, path)
+		}
+	}
+
+	for _, profile := range profiles {
+		if slices.Contains(opts.profiles, strings.TrimSpace(profile)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
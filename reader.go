@@ -5,39 +5,96 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
-	defaultRootPath   = "config"
-	defaultEnvVarName = "ENVIRONMENT"
+	defaultRootPath     = "config"
+	defaultEnvVarName   = "ENVIRONMENT"
+	defaultNameTemplate = profilePlaceholder
+)
+
+// Extensions of the profile file sources.
+var profileExtensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+// Placeholders of the naming template of the profile sources.
+const (
+	profilePlaceholder = "{profile}"
+	appPlaceholder     = "{app}"
 )
 
 type Reader interface {
 	SetRootPath(path string) Reader
+	SetRootPaths(paths ...string) Reader
 	SetEnvVariableName(name string) Reader
+	SetDefaultProfile(profile string) Reader
+	SetNameTemplate(template string) Reader
+	SetAppName(name string) Reader
 	SetReadAll(readAll bool) Reader
 	AddSource(source string) Reader
+	AddSources(sources ...Source) Reader
+	SetStrict(strict bool) Reader
+	SetReport(report *Report) Reader
+	SetKeyMatching(matching KeyMatching) Reader
+	SetMergePolicy(policy MergePolicy) Reader
+	SetListStrategy(path string, strategy ListStrategy) Reader
+	SetFileOrder(order FileOrder) Reader
+	SetManifest(name string) Reader
+	SetWatchInterval(interval time.Duration) Reader
+	SetReloadSignals(signals ...os.Signal) Reader
+	Profiles() ([]string, error)
 	Read(to any) error
+	ReadSection(to any, path string) error
+	LoadConfig() (*Config, error)
 }
 
 type reader struct {
-	rootPath   string
-	envVarName string
-	readAll    bool
-	sources    []string
+	rootPaths      []string
+	envVarName     string
+	defaultProfile string
+	nameTemplate   string
+	appName        string
+	readAll        bool
+	sources        []Source
+	options        *options
+
+	watchInterval time.Duration
+	reloadSignals []os.Signal
+
+	// Parsed files by their paths and active profiles
+	cacheMu sync.Mutex
+	cache   map[string]parsedFile
 }
 
 func NewReader() Reader {
 	return &reader{
-		rootPath:   defaultRootPath,
-		envVarName: defaultEnvVarName,
-		readAll:    true,
-		sources:    make([]string, 0),
+		rootPaths:      []string{defaultRootPath},
+		envVarName:     defaultEnvVarName,
+		defaultProfile: defaultProfile,
+		nameTemplate:   defaultNameTemplate,
+		readAll:        true,
+		sources:        make([]Source, 0),
+		options:        newOptions(),
+
+		watchInterval: defaultWatchInterval,
+		reloadSignals: defaultReloadSignals,
 	}
 }
 
 func (r *reader) SetRootPath(path string) Reader {
-	r.rootPath = path
+	r.rootPaths = []string{path}
+
+	return r
+}
+
+// SetRootPaths sets the root paths that are searched for the profile sources
+// in the priority order, the sources are read from the first root path that
+// contains them. The environment variables in the paths are expanded
+// (for example "$XDG_CONFIG_HOME/app").
+func (r *reader) SetRootPaths(paths ...string) Reader {
+	r.rootPaths = paths
 
 	return r
 }
@@ -48,6 +105,30 @@ func (r *reader) SetEnvVariableName(name string) Reader {
 	return r
 }
 
+// SetDefaultProfile sets the profile that is active if the environment variable is not set.
+func (r *reader) SetDefaultProfile(profile string) Reader {
+	r.defaultProfile = profile
+
+	return r
+}
+
+// SetNameTemplate sets the template of the profile source names relative to the root path
+// (for example "application-{profile}.yaml" or "{app}.{profile}.toml"). If the template
+// has no extension, the source is the directory or the file with any supported extension.
+// By default, the template is "{profile}".
+func (r *reader) SetNameTemplate(template string) Reader {
+	r.nameTemplate = template
+
+	return r
+}
+
+// SetAppName sets the value of the "{app}" placeholder of the naming template.
+func (r *reader) SetAppName(name string) Reader {
+	r.appName = name
+
+	return r
+}
+
 func (r *reader) SetReadAll(readAll bool) Reader {
 	r.readAll = readAll
 
@@ -59,92 +140,235 @@ func (r *reader) AddSource(source string) Reader {
 		panic("you can`t add source for reader when ReadAll = true")
 	}
 
-	r.sources = append(r.sources, source)
+	r.sources = append(r.sources, Source{Path: source})
+
+	return r
+}
+
+func (r *reader) SetStrict(strict bool) Reader {
+	r.options.strict = strict
+
+	return r
+}
+
+func (r *reader) SetReport(report *Report) Reader {
+	r.options.report = report
+
+	return r
+}
+
+func (r *reader) SetKeyMatching(matching KeyMatching) Reader {
+	r.options.keyMatching = matching
+
+	return r
+}
+
+func (r *reader) SetMergePolicy(policy MergePolicy) Reader {
+	r.options.mergePolicy = policy
+
+	return r
+}
+
+func (r *reader) SetListStrategy(path string, strategy ListStrategy) Reader {
+	WithListStrategy(path, strategy)(r.options)
+
+	return r
+}
+
+func (r *reader) SetFileOrder(order FileOrder) Reader {
+	r.options.fileOrder = order
+
+	return r
+}
+
+func (r *reader) SetManifest(name string) Reader {
+	r.options.manifest = name
+
+	return r
+}
+
+func (r *reader) SetWatchInterval(interval time.Duration) Reader {
+	r.watchInterval = interval
+
+	return r
+}
+
+func (r *reader) SetReloadSignals(signals ...os.Signal) Reader {
+	r.reloadSignals = signals
 
 	return r
 }
 
 func (r *reader) Read(to any) error {
-	env, ok := os.LookupEnv(r.envVarName)
-	if !ok {
-		env = "local"
-	}
+	return r.ReadSection(to, "")
+}
 
-	paths, err := getAllPaths(r.rootPath)
+func (r *reader) ReadSection(to any, path string) error {
+	data, opts, err := r.readData()
 	if err != nil {
 		return err
 	}
 
-	dirSource := filepath.Join(r.rootPath, env)
-	yamlSource := filepath.Join(r.rootPath, env+".yaml")
-	ymlSource := filepath.Join(r.rootPath, env+".yml")
-	jsonSource := filepath.Join(r.rootPath, env+".json")
-	tomlSource := filepath.Join(r.rootPath, env+".toml")
+	return fillConfigPath(to, data, path, opts)
+}
+
+func (r *reader) LoadConfig() (*Config, error) {
+	data, opts, err := r.readData()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{data: data, opts: opts}, nil
+}
 
-	dirSourceExists := slices.Contains(paths, dirSource)
-	fileSourceExists := slices.Contains(paths, yamlSource) ||
-		slices.Contains(paths, ymlSource) ||
-		slices.Contains(paths, jsonSource) ||
-		slices.Contains(paths, tomlSource)
+// readData reads the sources of the active profiles.
+func (r *reader) readData() (*configData, *options, error) {
+	layers, opts, err := r.resolve()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if dirSourceExists && fileSourceExists {
-		panic("confy: you can't use directory source and file source at the same time")
-	} else if !dirSourceExists && !fileSourceExists {
-		panic("confy: not a single source was found")
-	} else if fileSourceExists {
-		filePath := ""
-		fileFound := false
+	data, err := getLayersData(layers, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, opts, nil
+}
 
-		if slices.Contains(paths, yamlSource) {
-			fileFound = true
-			filePath = yamlSource
+// resolve returns the layers of the sources for the active profiles and the options
+// that activate the profile sections of the files. The first layer holds the shared
+// sources, every profile of the chain has its own layer that overrides the values
+// of the previous ones.
+func (r *reader) resolve() ([][]string, *options, error) {
+	profiles, err := r.Profiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources := r.resolveSharedSources(profiles)
+	layers := append(make([][]string, 0, len(profiles)+1), sources)
+
+	for _, profile := range profiles {
+		profileSources, err := r.resolveProfile(profile)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if slices.Contains(paths, ymlSource) {
-			if fileFound {
-				panic("confy: there can only be one file source")
+		// Every source is read once, in its first layer
+		layer := make([]string, 0, len(profileSources))
+
+		for _, source := range profileSources {
+			if !slices.Contains(sources, source) {
+				sources = append(sources, source)
+				layer = append(layer, source)
 			}
+		}
+
+		layers = append(layers, layer)
+	}
+
+	opts := *r.options
+	opts.profiles = profiles
 
-			fileFound = true
-			filePath = ymlSource
+	return layers, &opts, nil
+}
+
+// resolveProfile returns the paths of the sources for the profile
+// from the first root path that contains them.
+func (r *reader) resolveProfile(env string) ([]string, error) {
+	for _, rootPath := range r.getRootPaths() {
+		if sources, ok := r.resolveProfileIn(rootPath, env); ok {
+			return sources, nil
 		}
+	}
 
-		if slices.Contains(paths, jsonSource) {
-			if fileFound {
-				panic("confy: there can only be one file source")
-			}
+	candidates := make([]string, 0)
+
+	for _, rootPath := range r.getRootPaths() {
+		candidates = append(candidates, r.getProfileCandidates(rootPath, env)...)
+	}
+
+	panic(fmt.Sprintf("confy: not a single source was found for the '%s' profile by the '%s' name template, the checked paths: %s", env, r.nameTemplate, strings.Join(candidates, ", ")))
+}
+
+// getProfileCandidates returns the paths of the sources for the profile that are checked in the root path.
+func (r *reader) getProfileCandidates(rootPath, env string) []string {
+	name := r.getProfileName(env)
+
+	if slices.Contains(validExtensions, strings.ToLower(filepath.Ext(name))) {
+		return []string{filepath.Join(rootPath, name)}
+	}
+
+	candidates := []string{filepath.Join(rootPath, name)}
+
+	for _, ext := range profileExtensions {
+		candidates = append(candidates, filepath.Join(rootPath, name+ext))
+	}
+
+	return candidates
+}
+
+// resolveProfileIn returns the paths of the sources for the profile in the root path.
+// The second value is false if the root path doesn't contain the sources.
+func (r *reader) resolveProfileIn(rootPath, env string) ([]string, bool) {
+	name := r.getProfileName(env)
 
-			fileFound = true
-			filePath = jsonSource
+	// The template with the extension names the file source only
+	if slices.Contains(validExtensions, strings.ToLower(filepath.Ext(name))) {
+		fileSource := filepath.Join(rootPath, name)
+
+		if !isFile(fileSource) {
+			return nil, false
 		}
 
-		if slices.Contains(paths, tomlSource) {
-			if fileFound {
-				panic("confy: there can only be one file source")
-			}
+		return []string{fileSource}, true
+	}
+
+	dirSource := filepath.Join(rootPath, name)
 
-			filePath = tomlSource
+	fileSources := make([]string, 0, 1)
+
+	for _, ext := range profileExtensions {
+		if fileSource := filepath.Join(rootPath, name+ext); isFile(fileSource) {
+			fileSources = append(fileSources, fileSource)
 		}
+	}
 
-		return Read(to, filePath)
+	dirSourceExists := isDir(dirSource)
+	fileSourceExists := len(fileSources) > 0
+
+	if dirSourceExists && fileSourceExists {
+		panic("confy: you can't use directory source and file source at the same time")
+	} else if !dirSourceExists && !fileSourceExists {
+		return nil, false
+	} else if fileSourceExists {
+		if len(fileSources) > 1 {
+			panic("confy: there can only be one file source")
+		}
+
+		return fileSources, true
 	} else {
 		if r.readAll {
-			return Read(to, dirSource)
+			return []string{dirSource}, true
 		} else {
-			//TODO: add special sources for env support
-			toRead := r.sources
+			return r.resolveDirSources(dirSource, env), true
+		}
+	}
+}
 
-			for i := range toRead {
-				path := filepath.Join(dirSource, toRead[i])
+// getRootPaths returns the root paths with the expanded environment variables.
+func (r *reader) getRootPaths() []string {
+	rootPaths := make([]string, len(r.rootPaths))
 
-				if !slices.Contains(paths, path) {
-					panic(fmt.Sprintf("confy: source %s wasn`t found", path))
-				}
+	for i, rootPath := range r.rootPaths {
+		rootPaths[i] = os.ExpandEnv(rootPath)
+	}
 
-				toRead[i] = path
-			}
+	return rootPaths
+}
 
-			return ReadMany(to, toRead...)
-		}
-	}
+// getProfileName returns the name of the profile source by the naming template.
+func (r *reader) getProfileName(profile string) string {
+	return strings.NewReplacer(profilePlaceholder, profile, appPlaceholder, r.appName).Replace(r.nameTemplate)
 }
@@ -0,0 +1,77 @@
+package confy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newBenchRoot creates the root path with the files of many profiles,
+// the "local" profile extends the "base" profile.
+func newBenchRoot(b *testing.B, profiles int) string {
+	b.Helper()
+
+	root := b.TempDir()
+
+	for i := range profiles {
+		writeBenchFile(b, filepath.Join(root, fmt.Sprintf("profile-%d.yaml", i)), fmt.Sprintf("value: %d\n", i))
+	}
+
+	writeBenchFile(b, filepath.Join(root, "base.yaml"), "db:\n  host: localhost\n  port: 5432\n")
+	writeBenchFile(b, filepath.Join(root, "local.yaml"), "$extends: base\ndb:\n  host: db.local\n")
+
+	return root
+}
+
+func writeBenchFile(b *testing.B, path, content string) {
+	b.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkReaderResolve(b *testing.B) {
+	for _, profiles := range []int{100, 5000} {
+		b.Run(fmt.Sprintf("files=%d", profiles), func(b *testing.B) {
+			r := NewReader().SetRootPath(newBenchRoot(b, profiles)).(*reader)
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				if _, _, err := r.resolve(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReaderResolveRootPaths(b *testing.B) {
+	empty := make([]string, 0, 10)
+
+	for range 10 {
+		empty = append(empty, newBenchRoot(b, 500))
+	}
+
+	root := b.TempDir()
+	writeBenchFile(b, filepath.Join(root, "base.yaml"), "db:\n  port: 5432\n")
+	writeBenchFile(b, filepath.Join(root, "local.yaml"), "$extends: base\n")
+
+	// The profile files are found in the last root path only
+	for _, path := range empty {
+		os.Remove(filepath.Join(path, "base.yaml"))
+		os.Remove(filepath.Join(path, "local.yaml"))
+	}
+
+	r := NewReader().SetRootPaths(append(empty, root)...).(*reader)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, _, err := r.resolve(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
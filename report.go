@@ -0,0 +1,122 @@
+package confy
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// OriginKind is the kind of the source that supplied the value of a field.
+type OriginKind string
+
+const (
+	// OriginFile means that the value was read from a file.
+	OriginFile OriginKind = "file"
+
+	// OriginEnv means that the value was taken from an environment variable,
+	// either by the "env" tag or by the "${VAR}" expansion in a file.
+	OriginEnv OriginKind = "env"
+
+	// OriginDefault means that the default value was used,
+	// either from the "default" tag or from the "${VAR:default}" expansion in a file.
+	OriginDefault OriginKind = "default"
+
+	// OriginCode means that no source supplied the value,
+	// so the field has the zero value of its type.
+	OriginCode OriginKind = "code"
+)
+
+// Origin describes the source that supplied the value of a field.
+type Origin struct {
+	Kind OriginKind `json:"kind"`
+
+	// File, Position and Key are set if the value
+	// (or the name of the expanded variable) was read from a file.
+	File     string `json:"file,omitempty"`
+	Position string `json:"position,omitempty"`
+	Key      string `json:"key,omitempty"`
+
+	// Env is the name of the environment variable.
+	Env string `json:"env,omitempty"`
+}
+
+func (o Origin) String() string {
+	var b strings.Builder
+
+	b.WriteString(string(o.Kind))
+
+	if o.Env != "" {
+		fmt.Fprintf(&b, " '%s'", o.Env)
+	}
+
+	if o.Key != "" {
+		fmt.Fprintf(&b, " key '%s'", o.Key)
+	}
+
+	if o.Position != "" {
+		fmt.Fprintf(&b, " in '%s'", o.Position)
+	} else if o.File != "" {
+		fmt.Fprintf(&b, " in '%s'", o.File)
+	}
+
+	return b.String()
+}
+
+// Report maps the full names of the config fields (for example "Config.Db.Port")
+// to the origins of their values.
+type Report map[string]Origin
+
+func (r Report) String() string {
+	var b strings.Builder
+
+	for _, name := range slices.Sorted(maps.Keys(r)) {
+		fmt.Fprintf(&b, "%s: %s\n", name, r[name])
+	}
+
+	return b.String()
+}
+
+// WithReport fills the report with the origins of the field values.
+func WithReport(report *Report) Option {
+	return func(o *options) {
+		o.report = report
+	}
+}
+
+func (d *decoder) report(metadata *fieldMeta, origin Origin) {
+	if d.opts.report == nil {
+		return
+	}
+
+	if *d.opts.report == nil {
+		*d.opts.report = make(Report)
+	}
+
+	(*d.opts.report)[metadata.name] = origin
+}
+
+func (d *decoder) fileOrigin(data map[string]any, metadata *fieldMeta) Origin {
+	origin := Origin{
+		Kind: OriginFile,
+		Key:  metadata.path,
+	}
+
+	if pos, ok := d.data.positions[metadata.path]; ok {
+		origin.File = pos.file
+		origin.Position = pos.String()
+	}
+
+	if name, ok := getExpansionVar(data[metadata.key]); ok {
+		if _, ok := os.LookupEnv(name); ok {
+			origin.Kind = OriginEnv
+		} else {
+			origin.Kind = OriginDefault
+		}
+
+		origin.Env = name
+	}
+
+	return origin
+}
@@ -0,0 +1,69 @@
+package confy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+)
+
+// Secret holds a sensitive config value (password, token, etc).
+// It can be read from files, environment variables and default values
+// like a value of the T type, but it never prints its value:
+// String, GoString, Format, MarshalJSON, MarshalText and LogValue return a redacted placeholder.
+// Use Reveal to get the value.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps the value into the Secret.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the secret value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format prints the redacted placeholder for any verb of the fmt package,
+// so the value isn't printed by the verbs like %d or %v.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (s *Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secret is implemented by the pointers to the Secret types.
+type secret interface {
+	secretValue() reflect.Value
+}
+
+var secretType = reflect.TypeOf((*secret)(nil)).Elem()
+
+func isSecretType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(secretType)
+}
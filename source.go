@@ -0,0 +1,119 @@
+package confy
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// Source is the source of the Reader.
+type Source struct {
+	// Path of the file or directory relative to the profile directory, or to
+	// the first root path that contains it if the source is shared. The path can
+	// be a glob pattern, the matched paths are read in the lexical order.
+	Path string
+
+	// Shared sources are read for all profiles before the profile sources,
+	// regardless of whether the profile is a file or a directory.
+	Shared bool
+
+	// Optional sources are skipped if they don't exist.
+	Optional bool
+
+	// Profiles the source is read for. If empty, the source is read for all profiles.
+	Profiles []string
+}
+
+// AddSources adds the sources to the reader. Unlike the AddSource method,
+// the shared sources can be added when ReadAll = true.
+func (r *reader) AddSources(sources ...Source) Reader {
+	for _, source := range sources {
+		if r.readAll && !source.Shared {
+			panic("you can`t add source for reader when ReadAll = true")
+		}
+
+		r.sources = append(r.sources, source)
+	}
+
+	return r
+}
+
+// isActive checks whether the source is read for one of the profiles.
+func (s Source) isActive(profiles ...string) bool {
+	if len(s.Profiles) == 0 {
+		return true
+	}
+
+	for _, profile := range profiles {
+		if slices.Contains(s.Profiles, profile) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveSharedSources returns the paths of the shared sources for the profiles.
+func (r *reader) resolveSharedSources(profiles []string) []string {
+	paths := make([]string, 0)
+
+	for _, source := range r.sources {
+		if !source.Shared || !source.isActive(profiles...) {
+			continue
+		}
+
+		rootPaths := r.getRootPaths()
+
+		// The source is read from the first root path that contains it
+		for i, rootPath := range rootPaths {
+			optional := source.Optional || i < len(rootPaths)-1
+
+			if matches := matchSource(rootPath, source, optional); len(matches) > 0 {
+				paths = appendMissing(paths, matches...)
+
+				break
+			}
+		}
+	}
+
+	return paths
+}
+
+// resolveDirSources returns the paths of the profile sources in the profile directory.
+func (r *reader) resolveDirSources(dir, profile string) []string {
+	paths := make([]string, 0)
+
+	for _, source := range r.sources {
+		if !source.Shared && source.isActive(profile) {
+			paths = appendMissing(paths, matchSource(dir, source, source.Optional)...)
+		}
+	}
+
+	return paths
+}
+
+// matchSource returns the paths matched by the source in the directory.
+func matchSource(dir string, source Source, optional bool) []string {
+	pattern := filepath.Join(dir, source.Path)
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("confy: invalid source %s: %s", pattern, err.Error()))
+	}
+
+	if len(matches) == 0 && !optional {
+		panic(fmt.Sprintf("confy: source %s wasn`t found", pattern))
+	}
+
+	return matches
+}
+
+func appendMissing(paths []string, newPaths ...string) []string {
+	for _, path := range newPaths {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
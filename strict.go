@@ -0,0 +1,138 @@
+package confy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+func (d *decoder) markUsed(path string, whole bool) {
+	if d.opts.strict {
+		d.used[path] = d.used[path] || whole
+	}
+}
+
+func (d *decoder) markKnown(path, key string) {
+	if d.opts.strict {
+		d.known[path] = append(d.known[path], key)
+	}
+}
+
+// checkUnknownKeys returns an error for every key of the config data
+// that wasn't used by any field of the config struct.
+func (d *decoder) checkUnknownKeys(root any, path string) error {
+	if d.used[path] {
+		return nil
+	}
+
+	var errs []error
+
+	d.collectUnknownKeys(root, path, &errs)
+
+	return errors.Join(errs...)
+}
+
+// collectUnknownKeys checks the keys of the maps and the items of the lists
+// whose whole subtree wasn't used.
+func (d *decoder) collectUnknownKeys(value any, path string, errs *[]error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			keyPath := joinPath(path, key)
+
+			whole, ok := d.used[keyPath]
+			if !ok {
+				*errs = append(*errs, d.unknownKeyError(path, key))
+
+				continue
+			}
+
+			if !whole {
+				d.collectUnknownKeys(v[key], keyPath, errs)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			itemPath := indexPath(path, i)
+
+			if whole, ok := d.used[itemPath]; ok && !whole {
+				d.collectUnknownKeys(item, itemPath, errs)
+			}
+		}
+	}
+}
+
+// hasStructs checks whether the values of the type contain the structs whose
+// keys are checked separately, such as the elements of the slice of structs.
+func hasStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return !slices.Contains(specificTypes, t) && !isSecretType(t)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasStructs(t.Elem())
+	default:
+		return false
+	}
+}
+
+func (d *decoder) unknownKeyError(path, key string) error {
+	keyPath := joinPath(path, key)
+
+	msg := fmt.Sprintf("unknown key '%s' in '%s'", keyPath, d.data.positions.locate(keyPath, "config"))
+
+	if suggestion, ok := suggestKey(key, d.known[path]); ok {
+		msg += fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+
+	return errors.New(msg)
+}
+
+// suggestKey returns the known key that is the closest to the unknown one.
+func suggestKey(key string, known []string) (string, bool) {
+	best := ""
+	bestDistance := len(key)/2 + 1
+
+	for _, k := range known {
+		if distance := editDistance(key, k); distance < bestDistance {
+			best = k
+			bestDistance = distance
+		}
+	}
+
+	return best, best != ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -26,42 +26,166 @@ const (
 	defaultTag  = "default"
 	layoutTag   = "layout"
 	requiredTag = "required"
+	secretTag   = "secret"
+
+	// Reload tags
+	reloadTag    = "reload"
+	immutableTag = "immutable"
 
 	// Default values
 	defaultSeparator = ";"
 )
 
-func fillConfig(cfg any, data map[string]any, dataTag string) error {
+// decoder writes the config data to the config struct.
+type decoder struct {
+	data *configData
+	opts *options
+
+	// Data paths used by the fields, the value is true if
+	// the whole subtree of the path was used.
+	used map[string]bool
+
+	// Keys of the struct fields by the data path of the struct.
+	known map[string][]string
+
+	// Types of the struct and its inlined structs being processed,
+	// the struct that embeds itself is inlined once.
+	inlineTypes []reflect.Type
+}
+
+func fillConfig(cfg any, data *configData, opts *options) error {
+	return fillConfigPath(cfg, data, "", opts)
+}
+
+// fillConfigPath writes the data at the path (for example "db.hosts[0]")
+// to the value the cfg argument points to. The empty path means the whole data.
+func fillConfigPath(cfg any, data *configData, path string, opts *options) error {
 	out := reflect.ValueOf(cfg)
 
 	if out.Kind() == reflect.Pointer && !out.IsNil() {
 		out = out.Elem()
 	} else {
-		return errors.New("the 'to' argument must be a non-nil pointer to struct")
+		return errors.New("the 'to' argument must be a non-nil pointer")
+	}
+
+	root, ok, err := data.lookup(path)
+	if err != nil {
+		return fmt.Errorf("error while '%s' section read: %s", path, err.Error())
+	}
+
+	if !ok {
+		return fmt.Errorf("error while '%s' section read: the section is not set", path)
+	}
+
+	metadata := newRootMeta(out.Type().Name(), path, data.tag)
+
+	if path != "" {
+		metadata.name = path
+	} else if metadata.name == "" {
+		metadata.name = "config"
+	}
+
+	d := &decoder{
+		data:  data,
+		opts:  opts,
+		used:  make(map[string]bool),
+		known: make(map[string][]string),
+	}
+
+	if err := d.processRoot(out, root, metadata); err != nil {
+		return err
+	}
+
+	if opts.strict {
+		return d.checkUnknownKeys(root, path)
 	}
 
-	if out.Kind() != reflect.Struct {
-		return errors.New("the passed pointer does not point to the struct")
+	return nil
+}
+
+// processRoot writes the root value to the struct, map, slice or other value the 'to' argument points to.
+func (d *decoder) processRoot(out reflect.Value, root any, metadata *fieldMeta) error {
+	if out.Kind() == reflect.Struct && !slices.Contains(specificTypes, out.Type()) && !isSecretType(out.Type()) {
+		switch rootValue := root.(type) {
+		case nil:
+			return d.processStruct(out, make(map[string]any), metadata)
+		case map[string]any:
+			return d.processStruct(out, rootValue, metadata)
+		case []any:
+			return errors.New("the root of the config source is a list, it can't be written to the struct")
+		default:
+			return d.fieldError(metadata, true, fmt.Errorf("error while value parsing: invalid value for '%s' struct field", metadata.name))
+		}
+	}
+
+	d.markUsed(metadata.path, !hasStructs(out.Type()))
+
+	if root == nil {
+		return nil
+	}
+
+	value, expanded, envOk := expandValue(root)
+	if expanded && !envOk {
+		return nil
 	}
 
-	metadata := make(map[string]string)
-	metadata["dataTag"] = dataTag
-	metadata["name"] = out.Type().Name()
+	metadata.isValueEnv = expanded
+	metadata.isValueDefault = false
 
-	return processStruct(out, data, metadata)
+	if err := d.parseValue(out, value, metadata); err != nil {
+		return d.fieldError(metadata, true, err)
+	}
+
+	return nil
 }
 
-func processStruct(s reflect.Value, data map[string]any, metadata map[string]string) error {
+func (d *decoder) processStruct(s reflect.Value, data map[string]any, metadata *fieldMeta) error {
 	if s.Kind() != reflect.Struct {
-		return fmt.Errorf("internal error: field '%s' is not a struct, but it is passed as an argument to the processStruct function", metadata["name"])
+		return fmt.Errorf("internal error: field '%s' is not a struct, but it is passed as an argument to the processStruct function", metadata.name)
 	}
 
-	for i := range s.NumField() {
-		field := s.Field(i)
-		fieldStructType := s.Type().Field(i)
-		metadata := getFieldMetadata(fieldStructType, metadata)
+	inlineTypes := d.inlineTypes
+	defer func() { d.inlineTypes = inlineTypes }()
+
+	if metadata.inline {
+		d.inlineTypes = append(slices.Clip(inlineTypes), s.Type())
+	} else {
+		d.inlineTypes = []reflect.Type{s.Type()}
+	}
+
+	plan := getStructPlan(s.Type(), metadata.dataTag)
+
+	if !metadata.inline && plan.duplicate != nil {
+		return fmt.Errorf("error while struct parsing: the '%s' key is used by the '%s.%s' and '%s.%s' fields", joinPath(metadata.path, plan.duplicate.key), metadata.name, plan.duplicate.first, metadata.name, plan.duplicate.second)
+	}
+
+	for i := range plan.fields {
+		field := s.Field(plan.fields[i].index)
+		fieldMetadata := plan.fields[i].fieldMeta(metadata)
+
+		if fieldMetadata.skip {
+			continue
+		}
+
+		if fieldMetadata.inline && field.Kind() == reflect.Struct {
+			// The exported fields of the embedded struct can be set
+			// even if the embedded struct type is unexported
+			if err := d.processStruct(field, data, fieldMetadata); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if field.CanSet() && !fieldMetadata.inline {
+			d.markKnown(metadata.path, fieldMetadata.key)
 
-		if err := processField(field, data, metadata); err != nil {
+			if err := d.matchKey(data, metadata, fieldMetadata); err != nil {
+				return err
+			}
+		}
+
+		if err := d.processField(field, data, fieldMetadata); err != nil {
 			return err
 		}
 	}
@@ -69,15 +193,19 @@ func processStruct(s reflect.Value, data map[string]any, metadata map[string]str
 	return nil
 }
 
-func processField(f reflect.Value, data map[string]any, metadata map[string]string) error {
+func (d *decoder) processField(f reflect.Value, data map[string]any, metadata *fieldMeta) error {
 	if !f.CanSet() {
 		return nil
 	}
 
 	if f.Kind() == reflect.Pointer {
+		if metadata.inline && slices.Contains(d.inlineTypes, f.Type().Elem()) {
+			return nil
+		}
+
 		newValue := reflect.New(f.Type().Elem()).Elem()
 
-		if err := processField(newValue, data, metadata); err != nil {
+		if err := d.processField(newValue, data, metadata); err != nil {
 			return err
 		}
 
@@ -86,77 +214,110 @@ func processField(f reflect.Value, data map[string]any, metadata map[string]stri
 		return nil
 	}
 
-	if f.Kind() == reflect.Struct && !slices.Contains(specificTypes, f.Type()) {
-		structData, err := getStructData(data, metadata)
+	if f.Kind() == reflect.Struct && !slices.Contains(specificTypes, f.Type()) && !isSecretType(f.Type()) {
+		if metadata.inline {
+			return d.processStruct(f, data, metadata)
+		}
+
+		d.markUsed(metadata.path, false)
+
+		structData, err := d.getStructData(data, metadata)
 		if err != nil {
 			return err
 		}
 
-		return processStruct(f, structData, metadata)
+		return d.processStruct(f, structData, metadata)
 	}
 
-	return setFieldValue(f, data, metadata)
+	d.markUsed(metadata.path, !hasStructs(f.Type()))
+
+	return d.setFieldValue(f, data, metadata)
 }
 
-func getFieldMetadata(fieldStructType reflect.StructField, commonMetadata map[string]string) map[string]string {
-	metadata := make(map[string]string)
+func getMetadataKey(fieldStructType reflect.StructField, dataTag string) string {
+	key, ok := lookupTagName(fieldStructType, confyTag)
+	if !ok {
+		key, ok = lookupTagName(fieldStructType, dataTag)
+		if !ok {
+			key = strings.ToLower(fieldStructType.Name)
+		}
+	}
 
-	// Set required metadata
-	metadata["key"] = getMetadataKey(fieldStructType, commonMetadata)
-	metadata["name"] = getMetadataName(fieldStructType, commonMetadata)
-	metadata["required"] = getMetadataRequired(fieldStructType)
-	metadata["separator"] = getMetadataSeparator(fieldStructType)
+	return key
+}
 
-	// Set non-required metadata
-	env, ok := getMetadataEnv(fieldStructType)
-	if ok {
-		metadata["env"] = env
+// getMetadataInline checks whether the fields of the nested struct are read
+// from the data of the parent struct. The embedded structs without the key in the tag
+// and the struct fields with the "inline" or "squash" tag option are inlined.
+func getMetadataInline(fieldStructType reflect.StructField, dataTag string) bool {
+	t := fieldStructType.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
 	}
 
-	defaultValue, ok := getMetadataDefaultValue(fieldStructType)
-	if ok {
-		metadata["defaultValue"] = defaultValue
+	if t.Kind() != reflect.Struct || slices.Contains(specificTypes, t) || isSecretType(t) {
+		return false
 	}
 
-	layout, ok := getMetadataLayout(fieldStructType)
-	if ok {
-		metadata["layout"] = layout
+	options := append(lookupTagOptions(fieldStructType, confyTag), lookupTagOptions(fieldStructType, dataTag)...)
+
+	if slices.Contains(options, "inline") || slices.Contains(options, "squash") {
+		return true
 	}
 
-	return metadata
+	_, confyOk := lookupTagName(fieldStructType, confyTag)
+	_, dataOk := lookupTagName(fieldStructType, dataTag)
+
+	return fieldStructType.Anonymous && !confyOk && !dataOk
 }
 
-func getMetadataKey(fieldStructType reflect.StructField, metadata map[string]string) string {
-	key, ok := fieldStructType.Tag.Lookup(confyTag)
+// getMetadataSkip checks whether the field is ignored by the "-" tag.
+func getMetadataSkip(fieldStructType reflect.StructField, dataTag string) bool {
+	return fieldStructType.Tag.Get(confyTag) == "-" || fieldStructType.Tag.Get(dataTag) == "-"
+}
+
+// getMetadataOmitEmpty checks whether the field with the zero value is omitted in the dump.
+func getMetadataOmitEmpty(fieldStructType reflect.StructField, dataTag string) bool {
+	options := append(lookupTagOptions(fieldStructType, confyTag), lookupTagOptions(fieldStructType, dataTag)...)
+
+	return slices.Contains(options, "omitempty")
+}
+
+// lookupTagName returns the name part of the tag in the "name,option" format.
+// The result is false if the tag is not set or its name is empty.
+func lookupTagName(fieldStructType reflect.StructField, tag string) (string, bool) {
+	value, ok := fieldStructType.Tag.Lookup(tag)
 	if !ok {
-		key, ok = fieldStructType.Tag.Lookup(metadata["dataTag"])
-		if !ok {
-			key = strings.ToLower(fieldStructType.Name)
-		}
+		return "", false
 	}
 
-	return key
+	name, _, _ := strings.Cut(value, ",")
+
+	return name, name != ""
 }
 
-func getMetadataName(fieldStructType reflect.StructField, metadata map[string]string) string {
-	name, ok := metadata["name"]
-	if ok {
-		return fmt.Sprintf("%s.%s", name, fieldStructType.Name)
+// lookupTagOptions returns the options of the tag in the "name,option" format.
+func lookupTagOptions(fieldStructType reflect.StructField, tag string) []string {
+	value, ok := fieldStructType.Tag.Lookup(tag)
+	if !ok {
+		return nil
+	}
+
+	_, options, ok := strings.Cut(value, ",")
+	if !ok {
+		return nil
 	}
 
-	return fieldStructType.Name
+	return strings.Split(options, ",")
 }
 
-func getMetadataRequired(fieldStructType reflect.StructField) string {
+func getMetadataRequired(fieldStructType reflect.StructField) bool {
 	required, ok := fieldStructType.Tag.Lookup(requiredTag)
 	if !ok {
-		required, ok = fieldStructType.Tag.Lookup(envRequiredTag)
-		if !ok {
-			required = "false"
-		}
+		required = fieldStructType.Tag.Get(envRequiredTag)
 	}
 
-	return required
+	return required == "true"
 }
 
 func getMetadataSeparator(fieldStructType reflect.StructField) string {
@@ -168,6 +329,14 @@ func getMetadataSeparator(fieldStructType reflect.StructField) string {
 	return separator
 }
 
+func getMetadataSecret(fieldStructType reflect.StructField) bool {
+	return fieldStructType.Tag.Get(secretTag) == "true"
+}
+
+func getMetadataImmutable(fieldStructType reflect.StructField) bool {
+	return fieldStructType.Tag.Get(reloadTag) == "restart" || fieldStructType.Tag.Get(immutableTag) == "true"
+}
+
 func getMetadataEnv(fieldStructType reflect.StructField) (string, bool) {
 	return fieldStructType.Tag.Lookup(envTag)
 }
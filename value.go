@@ -7,8 +7,8 @@ import (
 	"strings"
 )
 
-func getStructData(data map[string]any, metadata map[string]string) (map[string]any, error) {
-	structData, ok := data[metadata["key"]]
+func (d *decoder) getStructData(data map[string]any, metadata *fieldMeta) (map[string]any, error) {
+	structData, ok := data[metadata.key]
 	if !ok {
 		return make(map[string]any), nil
 	}
@@ -16,19 +16,24 @@ func getStructData(data map[string]any, metadata map[string]string) (map[string]
 	if mapStructData, ok := structData.(map[string]any); ok {
 		return mapStructData, nil
 	} else {
-		return nil, fmt.Errorf("error while value parsing: invalid value for '%s' struct field", metadata["name"])
+		return nil, d.fieldError(metadata, true, fmt.Errorf("error while value parsing: invalid value for '%s' struct field", metadata.name))
 	}
 }
 
-func setFieldValue(f reflect.Value, data map[string]any, metadata map[string]string) error {
+func (d *decoder) setFieldValue(f reflect.Value, data map[string]any, metadata *fieldMeta) error {
 	value, fileOk, expanded := getFieldFileValue(data, metadata)
 
 	value, envOk := overrideValueWithEnv(value, metadata)
 
+	// The file value overridden by the environment variable isn't decoded
+	if fileOk && envOk {
+		d.markUsed(metadata.path, true)
+	}
+
 	if envOk || expanded {
-		metadata["isValueEnv"] = "true"
+		metadata.isValueEnv = true
 	} else {
-		metadata["isValueEnv"] = "false"
+		metadata.isValueEnv = false
 	}
 
 	if !(fileOk || envOk) {
@@ -37,28 +42,61 @@ func setFieldValue(f reflect.Value, data map[string]any, metadata map[string]str
 		value, defaultOk = getFieldDefaultValue(metadata)
 		if !defaultOk {
 			if isValueRequired(metadata) {
-				return fmt.Errorf("error while value parsing: value for '%s' field is required", metadata["name"])
+				return d.fieldError(metadata, false, fmt.Errorf("error while value parsing: value for '%s' field is required", metadata.name))
 			} else {
 				newValue := reflect.New(f.Type()).Elem()
 
 				f.Set(newValue)
 
+				d.report(metadata, Origin{Kind: OriginCode})
+
 				return nil
 			}
 		} else {
-			metadata["isValueDefault"] = "true"
+			metadata.isValueDefault = true
+
+			d.report(metadata, Origin{Kind: OriginDefault})
 		}
 	} else {
-		metadata["isValueDefault"] = "false"
+		metadata.isValueDefault = false
+
+		if envOk {
+			d.report(metadata, Origin{Kind: OriginEnv, Env: metadata.env})
+		} else {
+			d.report(metadata, d.fileOrigin(data, metadata))
+		}
 	}
 
-	return parseValue(f, value, metadata)
+	if err := d.parseValue(f, value, metadata); err != nil {
+		return d.fieldError(metadata, fileOk && !envOk, err)
+	}
+
+	return nil
 }
 
-func getFieldFileValue(data map[string]any, metadata map[string]string) (any, bool, bool) {
+// fieldError wraps the error of the field. If the value was read
+// from a file, the error contains the position of the value.
+func (d *decoder) fieldError(metadata *fieldMeta, fromFile bool, err error) error {
+	if _, ok := err.(*FieldError); ok {
+		return err
+	}
+
+	fieldErr := &FieldError{
+		Field: metadata.name,
+		Err:   err,
+	}
+
+	if pos, ok := d.data.positions[metadata.path]; ok && fromFile {
+		fieldErr.Position = pos.String()
+	}
+
+	return fieldErr
+}
+
+func getFieldFileValue(data map[string]any, metadata *fieldMeta) (any, bool, bool) {
 	var expanded bool
 
-	value, ok := data[metadata["key"]]
+	value, ok := data[metadata.key]
 	if ok {
 		var envOk bool
 
@@ -72,6 +110,20 @@ func getFieldFileValue(data map[string]any, metadata map[string]string) (any, bo
 	return value, ok, expanded
 }
 
+// getExpansionVar returns the name of the variable
+// from the value in the "${VAR:default}" format.
+func getExpansionVar(value any) (string, bool) {
+	if strVal, ok := value.(string); ok {
+		if len(strVal) > 3 && strVal[0] == '$' && strVal[1] == '{' && strVal[len(strVal)-1] == '}' {
+			name, _, _ := strings.Cut(strVal[2:len(strVal)-1], ":")
+
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
 func expandValue(value any) (any, bool, bool) {
 	expanded := false
 	envOk := false
@@ -96,8 +148,8 @@ func expandValue(value any) (any, bool, bool) {
 	return value, expanded, envOk
 }
 
-func overrideValueWithEnv(value any, metadata map[string]string) (any, bool) {
-	varName, ok := metadata["env"]
+func overrideValueWithEnv(value any, metadata *fieldMeta) (any, bool) {
+	varName, ok := metadata.env, metadata.hasEnv
 	if ok {
 		envValue, ok := os.LookupEnv(varName)
 		if ok {
@@ -110,8 +162,8 @@ func overrideValueWithEnv(value any, metadata map[string]string) (any, bool) {
 	}
 }
 
-func getFieldDefaultValue(metadata map[string]string) (any, bool) {
-	defaultValue, ok := metadata["defaultValue"]
+func getFieldDefaultValue(metadata *fieldMeta) (any, bool) {
+	defaultValue, ok := metadata.defaultValue, metadata.hasDefault
 	if !ok {
 		return nil, false
 	}
@@ -119,6 +171,6 @@ func getFieldDefaultValue(metadata map[string]string) (any, bool) {
 	return defaultValue, true
 }
 
-func isValueRequired(metadata map[string]string) bool {
-	return metadata["required"] == "true"
+func isValueRequired(metadata *fieldMeta) bool {
+	return metadata.required
 }
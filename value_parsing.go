@@ -2,6 +2,7 @@ package confy
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -13,7 +14,11 @@ var (
 	specificTypes = []reflect.Type{reflect.TypeOf(time.Time{}), reflect.TypeOf(url.URL{}), reflect.TypeOf(time.Location{}), reflect.TypeOf(time.Duration(0))}
 )
 
-func parseValue(f reflect.Value, value any, metadata map[string]string) error {
+func (d *decoder) parseValue(f reflect.Value, value any, metadata *fieldMeta) error {
+
+	if isSecretType(f.Type()) {
+		return d.parseValue(f.Addr().Interface().(secret).secretValue(), value, metadata)
+	}
 
 	switch f.Type() {
 
@@ -33,7 +38,11 @@ func parseValue(f reflect.Value, value any, metadata map[string]string) error {
 	switch f.Kind() {
 
 	case reflect.Interface:
-		f.Set(reflect.ValueOf(value))
+		if value == nil {
+			f.Set(reflect.Zero(f.Type()))
+		} else {
+			f.Set(reflect.ValueOf(value))
+		}
 
 		return nil
 
@@ -53,22 +62,36 @@ func parseValue(f reflect.Value, value any, metadata map[string]string) error {
 		return parseFloat(f, value, metadata)
 
 	case reflect.Map:
-		return parseMap(f, value, metadata)
+		return d.parseMap(f, value, metadata)
 
 	case reflect.Array:
-		return parseArray(f, value, metadata)
+		return d.parseArray(f, value, metadata)
 
 	case reflect.Slice:
-		return parseSlice(f, value, metadata)
+		return d.parseSlice(f, value, metadata)
+
+	case reflect.Struct:
+		return d.parseStruct(f, value, metadata)
+
+	case reflect.Pointer:
+		newValue := reflect.New(f.Type().Elem()).Elem()
+
+		if err := d.parseValue(newValue, value, metadata); err != nil {
+			return err
+		}
+
+		f.Set(newValue.Addr())
+
+		return nil
 
 	default:
-		return fmt.Errorf("error while value parsing: the '%v' type of the '%s' field is not supported", f.Type(), metadata["name"])
+		return fmt.Errorf("error while value parsing: the '%v' type of the '%s' field is not supported", f.Type(), metadata.name)
 
 	}
 }
 
-func parseTime(f reflect.Value, value any, metadata map[string]string) error {
-	layout, ok := metadata["layout"]
+func parseTime(f reflect.Value, value any, metadata *fieldMeta) error {
+	layout, ok := metadata.layout, metadata.hasLayout
 	if !ok {
 		layout = time.RFC3339
 	}
@@ -76,182 +99,212 @@ func parseTime(f reflect.Value, value any, metadata map[string]string) error {
 	if stringValue, ok := value.(string); ok {
 		timeValue, err := time.Parse(layout, stringValue)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Time", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Time", metadata.name)
 		}
 
 		f.Set(reflect.ValueOf(timeValue))
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata.name)
 	}
 
 	return nil
 }
 
-func parseTimeLocation(f reflect.Value, value any, metadata map[string]string) error {
+func parseTimeLocation(f reflect.Value, value any, metadata *fieldMeta) error {
 	if stringValue, ok := value.(string); ok {
 		locationValue, err := time.LoadLocation(stringValue)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Location", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Location", metadata.name)
 		}
 
 		f.Set(reflect.ValueOf(*locationValue))
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata.name)
 	}
 
 	return nil
 }
 
-func parseTimeDuration(f reflect.Value, value any, metadata map[string]string) error {
+func parseTimeDuration(f reflect.Value, value any, metadata *fieldMeta) error {
 	if stringValue, ok := value.(string); ok {
 		durationValue, err := time.ParseDuration(stringValue)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Duration", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be time.Duration", metadata.name)
 		}
 
 		f.Set(reflect.ValueOf(durationValue))
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata.name)
 	}
 
 	return nil
 }
 
-func parseUrl(f reflect.Value, value any, metadata map[string]string) error {
+func parseUrl(f reflect.Value, value any, metadata *fieldMeta) error {
 	if stringValue, ok := value.(string); ok {
 		urlValue, err := url.Parse(stringValue)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be URL", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be URL", metadata.name)
 		}
 
 		f.Set(reflect.ValueOf(*urlValue))
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata.name)
 	}
 
 	return nil
 }
 
-func parseString(f reflect.Value, value any, metadata map[string]string) error {
+func parseString(f reflect.Value, value any, metadata *fieldMeta) error {
 	if stringValue, ok := value.(string); ok {
 		f.SetString(stringValue)
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be string", metadata.name)
 	}
 
 	return nil
 }
 
-func parseBool(f reflect.Value, value any, metadata map[string]string) error {
+func parseBool(f reflect.Value, value any, metadata *fieldMeta) error {
 	if boolValue, ok := value.(bool); ok {
 		f.SetBool(boolValue)
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
 		boolValue, err := strconv.ParseBool(stringValue)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be bool", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be bool", metadata.name)
 		}
 
 		f.SetBool(boolValue)
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be bool", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be bool", metadata.name)
 	}
 
 	return nil
 }
 
-func parseInt(f reflect.Value, value any, metadata map[string]string) error {
-	if intValue, ok := value.(int); ok {
+func parseInt(f reflect.Value, value any, metadata *fieldMeta) error {
+	if intValue, ok := getIntValue(value); ok {
 		if !f.OverflowInt(int64(intValue)) {
 			f.SetInt(int64(intValue))
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
 		intValue, err := strconv.ParseInt(stringValue, 10, 64)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be int", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be int", metadata.name)
 		}
 
 		if !f.OverflowInt(int64(intValue)) {
 			f.SetInt(int64(intValue))
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be int", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be int", metadata.name)
 	}
 
 	return nil
 }
 
-func parseUint(f reflect.Value, value any, metadata map[string]string) error {
-	if uintValue, ok := value.(int); ok {
+func parseUint(f reflect.Value, value any, metadata *fieldMeta) error {
+	if uintValue, ok := getIntValue(value); ok && uintValue >= 0 {
 		if !f.OverflowUint(uint64(uintValue)) {
 			f.SetUint(uint64(uintValue))
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
 		uintValue, err := strconv.ParseUint(stringValue, 10, 64)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be uint", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be uint", metadata.name)
 		}
 
 		if !f.OverflowUint(uint64(uintValue)) {
 			f.SetUint(uint64(uintValue))
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be uint", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be uint", metadata.name)
 	}
 
 	return nil
 }
 
-func parseFloat(f reflect.Value, value any, metadata map[string]string) error {
-	if floatValue, ok := value.(float64); ok {
+func parseFloat(f reflect.Value, value any, metadata *fieldMeta) error {
+	if floatValue, ok := getFloatValue(value); ok {
 		if !f.OverflowFloat(floatValue) {
 			f.SetFloat(floatValue)
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
 		floatValue, err := strconv.ParseFloat(stringValue, 64)
 		if err != nil {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be float", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be float", metadata.name)
 		}
 
 		if !f.OverflowFloat(floatValue) {
 			f.SetFloat(floatValue)
 		} else {
-			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata["name"])
+			return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field is overflowed", metadata.name)
 		}
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be float", metadata["name"])
+		return fmt.Errorf("error while value parsing: invalid value. the value for '%s' field must be float", metadata.name)
 	}
 
 	return nil
 }
 
-func parseMap(f reflect.Value, value any, metadata map[string]string) error {
+// getIntValue returns the integer value of the file data. JSON numbers are
+// decoded as float64 and TOML integers as int64, so they are converted.
+func getIntValue(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float64:
+		return int64(v), v == math.Trunc(v) && v >= math.MinInt64 && v <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+func getFloatValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func (d *decoder) parseMap(f reflect.Value, value any, metadata *fieldMeta) error {
 	if f.Type().Key().Kind() != reflect.String {
-		return fmt.Errorf("error while value parsing: unsuppored type. type of '%s' field is a map with non-string key", metadata["name"])
+		return fmt.Errorf("error while value parsing: unsuppored type. type of '%s' field is a map with non-string key", metadata.name)
 	}
 
 	var data map[string]any
 
 	if mapValue, ok := value.(map[string]any); ok {
 		data = mapValue
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
-		items := strings.Split(stringValue, metadata["separator"])
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
+		items := strings.Split(stringValue, metadata.separator)
 		result := make(map[string]any)
 
 		for _, item := range items {
 			pair := strings.SplitN(item, ":", 2)
 
 			if len(pair) != 2 {
-				return fmt.Errorf("error while value parsing: invalid map value from environment for '%s' field", metadata["name"])
+				return fmt.Errorf("error while value parsing: invalid map value from environment for '%s' field", metadata.name)
 			}
 
 			result[pair[0]] = pair[1]
@@ -259,15 +312,20 @@ func parseMap(f reflect.Value, value any, metadata map[string]string) error {
 
 		data = result
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata["name"], f.Type())
+		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata.name, f.Type())
 	}
 
 	newMap := reflect.MakeMap(f.Type())
 
+	elemHasStructs := hasStructs(f.Type().Elem())
+
 	for k, v := range data {
 		newValue := reflect.New(f.Type().Elem()).Elem()
+		elemPath := joinPath(metadata.path, k)
 
-		if err := parseValue(newValue, v, metadata); err != nil {
+		d.markUsed(elemPath, !elemHasStructs)
+
+		if err := d.parseValue(newValue, v, metadata.elementMeta(k, elemPath)); err != nil {
 			return err
 		}
 
@@ -281,13 +339,13 @@ func parseMap(f reflect.Value, value any, metadata map[string]string) error {
 	return nil
 }
 
-func parseArray(f reflect.Value, value any, metadata map[string]string) error {
+func (d *decoder) parseArray(f reflect.Value, value any, metadata *fieldMeta) error {
 	var array []any
 
 	if arrayValue, ok := value.([]any); ok {
 		array = arrayValue
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
-		stringArray := strings.Split(stringValue, metadata["separator"])
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
+		stringArray := strings.Split(stringValue, metadata.separator)
 		var result []any
 
 		for _, v := range stringArray {
@@ -296,17 +354,22 @@ func parseArray(f reflect.Value, value any, metadata map[string]string) error {
 
 		array = result
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata["name"], f.Type())
+		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata.name, f.Type())
 	}
 
 	if len(array) > f.Type().Len() {
-		return fmt.Errorf("error while value parsing: invalid value. the array value for the '%s' field is longer then %d", metadata["name"], f.Type().Len())
+		return fmt.Errorf("error while value parsing: invalid value. the array value for the '%s' field is longer then %d", metadata.name, f.Type().Len())
 	}
 
+	elemHasStructs := hasStructs(f.Type().Elem())
+
 	for i := range array {
 		newValue := reflect.New(f.Type().Elem()).Elem()
+		elemPath := indexPath(metadata.path, i)
 
-		if err := parseValue(newValue, array[i], metadata); err != nil {
+		d.markUsed(elemPath, !elemHasStructs)
+
+		if err := d.parseValue(newValue, array[i], metadata.elementMeta(fmt.Sprintf("[%d]", i), elemPath)); err != nil {
 			return err
 		}
 
@@ -316,13 +379,13 @@ func parseArray(f reflect.Value, value any, metadata map[string]string) error {
 	return nil
 }
 
-func parseSlice(f reflect.Value, value any, metadata map[string]string) error {
+func (d *decoder) parseSlice(f reflect.Value, value any, metadata *fieldMeta) error {
 	var slice []any
 
 	if sliceValue, ok := value.([]any); ok {
 		slice = sliceValue
-	} else if stringValue, ok := value.(string); ok && (metadata["isValueEnv"] == "true" || metadata["isValueDefault"] == "true") {
-		stringArray := strings.Split(stringValue, metadata["separator"])
+	} else if stringValue, ok := value.(string); ok && (metadata.isValueEnv || metadata.isValueDefault) {
+		stringArray := strings.Split(stringValue, metadata.separator)
 		var result []any
 
 		for _, v := range stringArray {
@@ -331,13 +394,18 @@ func parseSlice(f reflect.Value, value any, metadata map[string]string) error {
 
 		slice = result
 	} else {
-		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata["name"], f.Type())
+		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata.name, f.Type())
 	}
 
+	elemHasStructs := hasStructs(f.Type().Elem())
+
 	for i := range slice {
 		newValue := reflect.New(f.Type().Elem()).Elem()
+		elemPath := indexPath(metadata.path, i)
 
-		if err := parseValue(newValue, slice[i], metadata); err != nil {
+		d.markUsed(elemPath, !elemHasStructs)
+
+		if err := d.parseValue(newValue, slice[i], metadata.elementMeta(fmt.Sprintf("[%d]", i), elemPath)); err != nil {
 			return err
 		}
 
@@ -346,3 +414,11 @@ func parseSlice(f reflect.Value, value any, metadata map[string]string) error {
 
 	return nil
 }
+
+func (d *decoder) parseStruct(f reflect.Value, value any, metadata *fieldMeta) error {
+	if mapValue, ok := value.(map[string]any); ok {
+		return d.processStruct(f, mapValue, metadata)
+	} else {
+		return fmt.Errorf("error while value parsing: invalid value. the value for the '%s' field must be '%v'", metadata.name, f.Type())
+	}
+}
@@ -0,0 +1,488 @@
+package confy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
+
+// ReloadTrigger is the cause of the config reload.
+type ReloadTrigger string
+
+const (
+	ReloadTriggerFile   ReloadTrigger = "file"
+	ReloadTriggerSignal ReloadTrigger = "signal"
+	ReloadTriggerManual ReloadTrigger = "manual"
+)
+
+// ReloadResult is the result of the config reload attempt.
+type ReloadResult struct {
+	Trigger ReloadTrigger
+	Time    time.Time
+	Err     error
+}
+
+// Validator is implemented by the configs that check themselves
+// after every load of the Watch function.
+type Validator interface {
+	Validate() error
+}
+
+// Handle gives access to the config that is reloaded when its sources change.
+type Handle[T any] struct {
+	reader *reader
+	value  atomic.Pointer[T]
+
+	// Semaphore that serializes the reloads
+	reloading chan struct{}
+
+	mu       sync.Mutex
+	snapshot map[string]fileState
+	err      error
+	onChange []func(old, new *T)
+	onError  []func(err error)
+	onReload []func(result ReloadResult)
+
+	// Callbacks of the fields by their data paths
+	subscribers map[string][]func(old, new any)
+}
+
+// Watch reads the config with the reader and then polls the sources of the
+// reader with its watch interval. When any file of the sources changes, the config
+// is read again, validated and published atomically. If the reload fails, the
+// previous config is kept and the error is passed to the OnError callbacks.
+//
+// The fields with the `reload:"restart"` or `immutable:"true"` tags keep their
+// values on reload. If they are changed in the sources, the other fields are
+// applied and the error listing the immutable fields is reported.
+//
+// The config is also reloaded when the process receives one of the reload signals
+// of the reader (SIGHUP by default) and when the Reload method is called.
+// The reloads are serialized.
+//
+// Watching stops when the context is done.
+func Watch[T any](ctx context.Context, r Reader) (*Handle[T], error) {
+	rd, ok := r.(*reader)
+	if !ok {
+		return nil, errors.New("the 'r' argument must be created by confy.NewReader")
+	}
+
+	h := &Handle[T]{
+		reader:    rd,
+		reloading: make(chan struct{}, 1),
+	}
+
+	snapshot, err := rd.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := loadValue[T](rd)
+	if err != nil {
+		return nil, err
+	}
+
+	h.snapshot = snapshot
+	h.value.Store(value)
+
+	go h.watch(ctx)
+
+	return h, nil
+}
+
+// Load returns the current config. The returned value must not be modified.
+func (h *Handle[T]) Load() *T {
+	return h.value.Load()
+}
+
+// Err returns the error of the last reload, or nil if it succeeded.
+func (h *Handle[T]) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+// OnChange registers the callback that is called after the new config is published.
+// The reloaded config that is equal to the current one is not published.
+func (h *Handle[T]) OnChange(fn func(old, new *T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onChange = append(h.onChange, fn)
+}
+
+// Subscribe registers the callback that is called after the new config is published,
+// if the value of the field with the data path (for example "db.pool.size") changed.
+// For the struct fields the callback is called if any of the nested fields changed.
+func (h *Handle[T]) Subscribe(path string, fn func(old, new any)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers == nil {
+		h.subscribers = make(map[string][]func(old, new any))
+	}
+
+	h.subscribers[path] = append(h.subscribers[path], fn)
+}
+
+// OnError registers the callback that is called when the reload fails.
+func (h *Handle[T]) OnError(fn func(err error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onError = append(h.onError, fn)
+}
+
+// OnReload registers the callback that is called after every reload attempt.
+func (h *Handle[T]) OnReload(fn func(result ReloadResult)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onReload = append(h.onReload, fn)
+}
+
+// Reload reads the config again, regardless of whether its sources changed.
+// If another reload is in progress, Reload waits for it to finish or for the context to be done.
+func (h *Handle[T]) Reload(ctx context.Context) error {
+	return h.trigger(ctx, ReloadTriggerManual)
+}
+
+func (h *Handle[T]) watch(ctx context.Context) {
+	interval := h.reader.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	signals := make(chan os.Signal, 1)
+
+	if len(h.reader.reloadSignals) > 0 {
+		signal.Notify(signals, h.reader.reloadSignals...)
+		defer signal.Stop(signals)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.trigger(ctx, ReloadTriggerFile)
+		case <-signals:
+			h.trigger(ctx, ReloadTriggerSignal)
+		}
+	}
+}
+
+// trigger reloads the config. The file trigger reloads it only if the sources changed.
+func (h *Handle[T]) trigger(ctx context.Context, trigger ReloadTrigger) error {
+	select {
+	case h.reloading <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-h.reloading }()
+
+	if !h.changed() && trigger == ReloadTriggerFile {
+		return nil
+	}
+
+	err := h.reload()
+
+	h.mu.Lock()
+	callbacks := slices.Clone(h.onReload)
+	h.mu.Unlock()
+
+	result := ReloadResult{
+		Trigger: trigger,
+		Time:    time.Now(),
+		Err:     err,
+	}
+
+	for _, fn := range callbacks {
+		fn(result)
+	}
+
+	return err
+}
+
+// changed checks whether the sources changed since the last check.
+func (h *Handle[T]) changed() bool {
+	snapshot, err := h.reader.snapshot()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		// The failed check is reported by the reload only once
+		changed := h.snapshot != nil
+		h.snapshot = nil
+
+		return changed
+	}
+
+	if maps.Equal(snapshot, h.snapshot) {
+		return false
+	}
+
+	h.snapshot = snapshot
+
+	return true
+}
+
+func (h *Handle[T]) reload() error {
+	value, err := loadValue[T](h.reader)
+	if err != nil {
+		return h.fail(fmt.Errorf("confy: config reload failed: %w", err))
+	}
+
+	h.mu.Lock()
+	dataTag := getSnapshotTag(h.snapshot)
+	h.mu.Unlock()
+
+	// The immutable fields keep their values, the other fields are applied
+	restored := restoreImmutable(reflect.ValueOf(h.value.Load()).Elem(), reflect.ValueOf(value).Elem(), dataTag)
+
+	// The same config is not published again
+	if reflect.DeepEqual(h.value.Load(), value) {
+		h.mu.Lock()
+		h.err = nil
+		h.mu.Unlock()
+
+		return h.failRestored(restored)
+	}
+
+	old := h.value.Swap(value)
+
+	h.mu.Lock()
+	h.err = nil
+	callbacks := slices.Clone(h.onChange)
+	subscribers := maps.Clone(h.subscribers)
+	h.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(old, value)
+	}
+
+	if len(subscribers) > 0 {
+		changes := diffConfigs(reflect.ValueOf(old).Elem(), reflect.ValueOf(value).Elem(), dataTag)
+
+		for _, path := range slices.Sorted(maps.Keys(subscribers)) {
+			if c, ok := changes[path]; ok {
+				for _, fn := range subscribers[path] {
+					fn(c.old, c.new)
+				}
+			}
+		}
+	}
+
+	return h.failRestored(restored)
+}
+
+// failRestored reports the immutable fields whose new values were not applied.
+func (h *Handle[T]) failRestored(restored []string) error {
+	if len(restored) > 0 {
+		return h.fail(fmt.Errorf("confy: the values of the '%s' fields can't be changed without restart", strings.Join(restored, "', '")))
+	}
+
+	return nil
+}
+
+// fail saves the error of the reload and passes it to the callbacks.
+func (h *Handle[T]) fail(err error) error {
+	h.mu.Lock()
+	h.err = err
+	callbacks := slices.Clone(h.onError)
+	h.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+
+	return err
+}
+
+func loadValue[T any](r *reader) (*T, error) {
+	value := new(T)
+
+	err := catch(func() error {
+		return r.Read(value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := any(value).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("config validation failed: %w", err)
+		}
+	}
+
+	return value, nil
+}
+
+// fileState is the state of the file used to detect the changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+
+	// The manifests and the imported files are not the sources
+	extra bool
+}
+
+// snapshot returns the states of all files of the sources.
+func (r *reader) snapshot() (map[string]fileState, error) {
+	var layers [][]string
+	var opts *options
+
+	err := catch(func() error {
+		var err error
+
+		layers, opts, err = r.resolve()
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sources := slices.Concat(layers...)
+
+	snapshot := make(map[string]fileState)
+
+	for _, source := range sources {
+		fi, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+
+		files := []string{source}
+
+		if fi.IsDir() {
+			files, err = getValidFiles(source, r.options)
+			if err != nil {
+				return nil, err
+			}
+
+			// The changes of the manifest change the list of the files
+			if r.options.manifest != "" {
+				manifest := filepath.Join(source, r.options.manifest)
+
+				if fi, err := os.Stat(manifest); err == nil {
+					snapshot[manifest] = fileState{modTime: fi.ModTime(), size: fi.Size(), extra: true}
+				}
+			}
+		}
+
+		for _, file := range files {
+			fi, err := os.Stat(file)
+			if err != nil {
+				return nil, err
+			}
+
+			snapshot[file] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+
+			if err := r.snapshotImports(file, filepath.Dir(file), opts, snapshot); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// snapshotImports adds the states of the files imported by the file to the snapshot.
+// The root is the directory that the imported files must be inside of.
+func (r *reader) snapshotImports(file, root string, opts *options, snapshot map[string]fileState) error {
+	// The env files have no keys and are loaded on parsing
+	if strings.ToLower(filepath.Ext(file)) == ".env" {
+		return nil
+	}
+
+	data, err := r.parseCached(file, opts)
+	if err != nil {
+		return err
+	}
+
+	value, ok := data.values[importKey]
+	if !ok {
+		return nil
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	p := &fileParser{opts: opts, root: absRoot}
+
+	imports, err := p.resolveImports(file, value)
+	if err != nil {
+		return err
+	}
+
+	for _, importPath := range imports {
+		// The files in the snapshot are checked already, it also stops the import cycles
+		if _, ok := snapshot[importPath]; ok {
+			continue
+		}
+
+		fi, err := os.Stat(importPath)
+		if err != nil {
+			return err
+		}
+
+		snapshot[importPath] = fileState{modTime: fi.ModTime(), size: fi.Size(), extra: true}
+
+		if err := r.snapshotImports(importPath, root, opts, snapshot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// catch converts the panic of the function to the error.
+func catch(fn func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+
+	return fn()
+}
+
+// getSnapshotTag returns the data tag of the snapshot files.
+func getSnapshotTag(snapshot map[string]fileState) string {
+	if len(snapshot) == 0 {
+		return confyTag
+	}
+
+	paths := make([]string, 0, len(snapshot))
+
+	for path, state := range snapshot {
+		if !state.extra {
+			paths = append(paths, path)
+		}
+	}
+
+	return getMultipleFilesTag(paths)
+}